@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Local history of the aim test sessions
+const aimFile = "aimtests.yml"
+
+const (
+	aimClick    = "Click"
+	aimTracking = "Tracking"
+)
+
+var aimModes = []string{aimClick, aimTracking}
+
+type aimSession struct {
+	Profile string
+	Mode    string
+	Date    string
+	Targets int
+	Hits    int
+	Misses  int
+	// Times the cursor left a target it had already reached
+	Overshoots int
+	// Mean milliseconds from a target appearing to its hit
+	TimeToTarget float64
+	// Mean straight distance over travelled distance, 1 is a perfect line
+	Efficiency float64
+	// Share of the tracking time spent on the target
+	OnTarget float64
+}
+
+type aimSummary struct {
+	Profile  string
+	Mode     string
+	Sessions int
+	// Counts are summed, the other values averaged
+	Stats aimSession
+}
+
+func loadAimSessions() ([]aimSession, error) {
+	var sessions []aimSession
+	data, err := ioutil.ReadFile(aimFile)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = yaml.Unmarshal(data, &sessions)
+	return sessions, err
+}
+
+func saveAimSession(session aimSession) error {
+	sessions, err := loadAimSessions()
+	if err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(append(sessions, session))
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(aimFile, data, 0644)
+}
+
+// Mean of every value per profile and mode
+func summarizeAimSessions(sessions []aimSession) []aimSummary {
+	index := map[string]int{}
+	var summaries []aimSummary
+	for _, s := range sessions {
+		key := s.Profile + "\x00" + s.Mode
+		i, ok := index[key]
+		if !ok {
+			i = len(summaries)
+			index[key] = i
+			summaries = append(summaries, aimSummary{Profile: s.Profile, Mode: s.Mode})
+		}
+		sum := &summaries[i]
+		sum.Sessions++
+		sum.Stats.Targets += s.Targets
+		sum.Stats.Hits += s.Hits
+		sum.Stats.Misses += s.Misses
+		sum.Stats.Overshoots += s.Overshoots
+		sum.Stats.TimeToTarget += s.TimeToTarget
+		sum.Stats.Efficiency += s.Efficiency
+		sum.Stats.OnTarget += s.OnTarget
+	}
+	for i := range summaries {
+		n := float64(summaries[i].Sessions)
+		summaries[i].Stats.TimeToTarget /= n
+		summaries[i].Stats.Efficiency /= n
+		summaries[i].Stats.OnTarget /= n
+	}
+	sort.SliceStable(summaries, func(i, j int) bool {
+		if summaries[i].Mode != summaries[j].Mode {
+			return summaries[i].Mode < summaries[j].Mode
+		}
+		return summaries[i].Profile < summaries[j].Profile
+	})
+	return summaries
+}
+
+func (s aimSummary) accuracy() float64 {
+	clicks := s.Stats.Hits + s.Stats.Misses
+	if clicks == 0 {
+		return 0
+	}
+	return float64(s.Stats.Hits) / float64(clicks)
+}
+
+func aimSummaryTable(summaries []aimSummary) string {
+	if len(summaries) == 0 {
+		return "No session yet"
+	}
+	var lines []string
+	lines = append(lines, fmt.Sprintf("%-20s %-9s %4s %8s %10s %9s %10s %9s",
+		"Profile", "Mode", "Runs", "Accuracy", "Overshoot", "Time ms", "Efficiency", "OnTarget"))
+	for _, s := range summaries {
+		overshoot := 0.0
+		if s.Stats.Targets > 0 {
+			overshoot = float64(s.Stats.Overshoots) / float64(s.Stats.Targets)
+		}
+		profile := s.Profile
+		if profile == "" {
+			profile = "(unsaved)"
+		}
+		line := fmt.Sprintf("%-20s %-9s %4d", profile, s.Mode, s.Sessions)
+		if s.Mode == aimTracking {
+			line += fmt.Sprintf(" %8s %10s %9s %10s %9s", "", "", "", "", formatFloat(s.Stats.OnTarget*100, 1)+"%")
+		} else {
+			line += fmt.Sprintf(" %8s %10s %9s %10s",
+				formatFloat(s.accuracy()*100, 1)+"%", formatFloat(overshoot, 2), formatFloat(s.Stats.TimeToTarget, 0), formatFloat(s.Stats.Efficiency, 3))
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
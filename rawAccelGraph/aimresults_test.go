@@ -0,0 +1,80 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSummarizeAimSessions(t *testing.T) {
+	sessions := []aimSession{
+		{Profile: "fast.yml", Mode: aimTracking, OnTarget: 0.5},
+		{Profile: "fast.yml", Mode: aimClick, Targets: 10, Hits: 8, Misses: 2, Overshoots: 3, TimeToTarget: 400, Efficiency: 0.8},
+		{Profile: "slow.yml", Mode: aimClick, Targets: 20, Hits: 15, Misses: 5, TimeToTarget: 500, Efficiency: 0.7},
+		{Profile: "fast.yml", Mode: aimClick, Targets: 10, Hits: 10, Overshoots: 1, TimeToTarget: 300, Efficiency: 0.9},
+		{Profile: "fast.yml", Mode: aimTracking, OnTarget: 0.7},
+		{Mode: aimClick, Targets: 4, Hits: 2, Misses: 2, TimeToTarget: 800, Efficiency: 0.5},
+	}
+	summaries := summarizeAimSessions(sessions)
+	want := []struct {
+		profile, mode string
+		sessions      int
+		targets, hits int
+		time, eff, on float64
+		accuracy      float64
+	}{
+		{"", aimClick, 1, 4, 2, 800, 0.5, 0, 0.5},
+		{"fast.yml", aimClick, 2, 20, 18, 350, 0.85, 0, 0.9},
+		{"slow.yml", aimClick, 1, 20, 15, 500, 0.7, 0, 0.75},
+		{"fast.yml", aimTracking, 2, 0, 0, 0, 0, 0.6, 0},
+	}
+	if len(summaries) != len(want) {
+		t.Fatalf("got %+v, want %d summaries", summaries, len(want))
+	}
+	for i, w := range want {
+		s := summaries[i]
+		if s.Profile != w.profile || s.Mode != w.mode || s.Sessions != w.sessions {
+			t.Errorf("summary %d is %s %s with %d sessions, want %s %s with %d", i, s.Profile, s.Mode, s.Sessions, w.profile, w.mode, w.sessions)
+			continue
+		}
+		if s.Stats.Targets != w.targets || s.Stats.Hits != w.hits {
+			t.Errorf("%s %s: %d hits of %d targets, want %d of %d", w.profile, w.mode, s.Stats.Hits, s.Stats.Targets, w.hits, w.targets)
+		}
+		if !almostEqual(s.Stats.TimeToTarget, w.time) || !almostEqual(s.Stats.Efficiency, w.eff) || !almostEqual(s.Stats.OnTarget, w.on) {
+			t.Errorf("%s %s: averages %+v, want time %v, efficiency %v, on target %v", w.profile, w.mode, s.Stats, w.time, w.eff, w.on)
+		}
+		if !almostEqual(s.accuracy(), w.accuracy) {
+			t.Errorf("%s %s: accuracy %v, want %v", w.profile, w.mode, s.accuracy(), w.accuracy)
+		}
+	}
+}
+
+func TestAimSummaryTable(t *testing.T) {
+	if got := aimSummaryTable(nil); got != "No session yet" {
+		t.Errorf("empty table %q", got)
+	}
+	summaries := []aimSummary{
+		{Profile: "fast.yml", Mode: aimClick, Sessions: 2, Stats: aimSession{Targets: 20, Hits: 18, Misses: 2, Overshoots: 4, TimeToTarget: 350, Efficiency: 0.85}},
+		{Mode: aimClick, Sessions: 1},
+		{Profile: "fast.yml", Mode: aimTracking, Sessions: 2, Stats: aimSession{OnTarget: 0.6}},
+	}
+	lines := strings.Split(aimSummaryTable(summaries), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("got %d lines, want a header and 3 rows:\n%s", len(lines), strings.Join(lines, "\n"))
+	}
+	for i, want := range [][]string{
+		{"Profile", "Mode", "Runs", "Accuracy", "Overshoot", "Time", "ms", "Efficiency", "OnTarget"},
+		// Overshoots per target
+		{"fast.yml", aimClick, "2", "90.0%", "0.20", "350", "0.850"},
+		// No click and no target do not divide by zero
+		{"(unsaved)", aimClick, "1", "0.0%", "0.00", "0", "0.000"},
+		// Tracking only fills the last column
+		{"fast.yml", aimTracking, "2", "60.0%"},
+	} {
+		if got := strings.Fields(lines[i]); strings.Join(got, " ") != strings.Join(want, " ") {
+			t.Errorf("line %d = %q, want %v", i, lines[i], want)
+		}
+	}
+	if column := strings.Index(lines[0], "OnTarget"); !strings.HasSuffix(lines[3][:column+len("OnTarget")], "60.0%") {
+		t.Errorf("tracking value %q not under OnTarget", lines[3])
+	}
+}
@@ -0,0 +1,331 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	aimTargets        = 20
+	aimTrackingLength = 30 * time.Second
+	aimRadius         = 18
+	aimFrame          = 16 * time.Millisecond
+)
+
+// Area showing one target at a time, scoring the pointer moves and clicks
+type aimArena struct {
+	widget.BaseWidget
+	OnFinished func(aimSession)
+	OnProgress func(string)
+
+	mu           sync.Mutex
+	mode         string
+	active       bool
+	session      aimSession
+	target       fyne.Position
+	cursor       fyne.Position
+	started      time.Time
+	stop         chan struct{}
+	circle       *canvas.Circle
+	efficiencies float64
+
+	// Current click target
+	appeared time.Time
+	origin   fyne.Position
+	path     float32
+	reached  bool
+	inside   bool
+}
+
+func newAimArena() *aimArena {
+	arena := &aimArena{circle: canvas.NewCircle(theme.PrimaryColor())}
+	arena.circle.Hide()
+	arena.ExtendBaseWidget(arena)
+	return arena
+}
+
+type aimArenaRenderer struct {
+	arena      *aimArena
+	background *canvas.Rectangle
+}
+
+func (r *aimArenaRenderer) Layout(size fyne.Size) {
+	r.background.Resize(size)
+	r.arena.moveCircle(r.arena.targetPosition())
+}
+
+func (r *aimArenaRenderer) MinSize() fyne.Size {
+	return fyne.NewSize(600, 400)
+}
+
+func (r *aimArenaRenderer) Refresh() {
+	r.background.FillColor = theme.InputBackgroundColor()
+	r.background.Refresh()
+	r.arena.moveCircle(r.arena.targetPosition())
+}
+
+func (r *aimArenaRenderer) Objects() []fyne.CanvasObject {
+	return []fyne.CanvasObject{r.background, r.arena.circle}
+}
+
+func (r *aimArenaRenderer) Destroy() {}
+
+func (a *aimArena) CreateRenderer() fyne.WidgetRenderer {
+	return &aimArenaRenderer{arena: a, background: canvas.NewRectangle(theme.InputBackgroundColor())}
+}
+
+func (a *aimArena) targetPosition() fyne.Position {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.target
+}
+
+func (a *aimArena) moveCircle(target fyne.Position) {
+	a.circle.Resize(fyne.NewSize(2*aimRadius, 2*aimRadius))
+	a.circle.Move(fyne.NewPos(target.X-aimRadius, target.Y-aimRadius))
+	canvas.Refresh(a.circle)
+}
+
+func distance(a, b fyne.Position) float32 {
+	return float32(math.Hypot(float64(a.X-b.X), float64(a.Y-b.Y)))
+}
+
+func (a *aimArena) Start(mode string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.stopLocked()
+	a.mode = mode
+	a.active = true
+	a.started = time.Now()
+	a.efficiencies = 0
+	a.session = aimSession{
+		Profile: currentProfile,
+		Mode:    mode,
+		Date:    time.Now().Format("2006-01-02 15:04"),
+	}
+	a.circle.Show()
+	if mode == aimTracking {
+		a.stop = make(chan struct{})
+		go a.track(a.stop)
+		return
+	}
+	a.newTarget()
+}
+
+func (a *aimArena) Stop() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.stopLocked()
+	a.active = false
+	a.circle.Hide()
+}
+
+func (a *aimArena) stopLocked() {
+	if a.stop != nil {
+		close(a.stop)
+		a.stop = nil
+	}
+}
+
+func (a *aimArena) newTarget() {
+	size := a.Size()
+	margin := float32(aimRadius * 2)
+	a.target = fyne.NewPos(
+		margin+rand.Float32()*fyne.Max(size.Width-2*margin, 1),
+		margin+rand.Float32()*fyne.Max(size.Height-2*margin, 1),
+	)
+	a.appeared = time.Now()
+	a.origin = a.cursor
+	a.path = 0
+	a.reached = false
+	a.inside = false
+	a.moveCircle(a.target)
+}
+
+// Moves the target on a Lissajous curve and counts the time the cursor is on it
+func (a *aimArena) track(stop chan struct{}) {
+	ticker := time.NewTicker(aimFrame)
+	defer ticker.Stop()
+	last := time.Now()
+	var onTarget time.Duration
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			a.mu.Lock()
+			// Stopped or restarted while waiting for the lock
+			if a.stop != stop {
+				a.mu.Unlock()
+				return
+			}
+			elapsed := now.Sub(a.started)
+			if distance(a.cursor, a.target) <= aimRadius {
+				onTarget += now.Sub(last)
+			}
+			last = now
+			size := a.Size()
+			t := elapsed.Seconds()
+			a.target = fyne.NewPos(
+				size.Width/2+(size.Width/2-aimRadius*2)*float32(math.Sin(1.3*t)),
+				size.Height/2+(size.Height/2-aimRadius*2)*float32(math.Sin(1.7*t+0.5)),
+			)
+			a.session.OnTarget = onTarget.Seconds() / elapsed.Seconds()
+			a.moveCircle(a.target)
+			if elapsed >= aimTrackingLength {
+				session := a.finishLocked()
+				a.mu.Unlock()
+				a.finished(session)
+				return
+			}
+			progress := fmt.Sprintf("%s on target, %s s left", formatFloat(a.session.OnTarget*100, 1)+"%",
+				formatFloat((aimTrackingLength-elapsed).Seconds(), 0))
+			a.mu.Unlock()
+			a.progress(progress)
+		}
+	}
+}
+
+func (a *aimArena) MouseIn(e *desktop.MouseEvent) {
+	a.mu.Lock()
+	a.cursor = e.Position
+	a.mu.Unlock()
+}
+
+func (a *aimArena) MouseMoved(e *desktop.MouseEvent) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.active && a.mode == aimClick {
+		a.path += distance(a.cursor, e.Position)
+		inside := distance(e.Position, a.target) <= aimRadius
+		if a.reached && a.inside && !inside {
+			a.session.Overshoots++
+		}
+		if inside {
+			a.reached = true
+		}
+		a.inside = inside
+	}
+	a.cursor = e.Position
+}
+
+func (a *aimArena) MouseOut() {}
+
+func (a *aimArena) Tapped(e *fyne.PointEvent) {
+	a.mu.Lock()
+	if !a.active || a.mode != aimClick {
+		a.mu.Unlock()
+		return
+	}
+	if distance(e.Position, a.target) > aimRadius {
+		a.session.Misses++
+		a.mu.Unlock()
+		return
+	}
+	a.session.Hits++
+	a.session.Targets++
+	a.session.TimeToTarget += float64(time.Since(a.appeared)) / float64(time.Millisecond)
+	efficiency := 1.0
+	if a.path > 0 {
+		efficiency = math.Min(float64(distance(a.origin, e.Position)/a.path), 1)
+	}
+	a.efficiencies += efficiency
+	if a.session.Targets >= aimTargets {
+		session := a.finishLocked()
+		a.mu.Unlock()
+		a.finished(session)
+		return
+	}
+	progress := fmt.Sprintf("%d / %d targets, %d missed clicks", a.session.Targets, aimTargets, a.session.Misses)
+	a.newTarget()
+	a.mu.Unlock()
+	a.progress(progress)
+}
+
+func (a *aimArena) progress(text string) {
+	if a.OnProgress != nil {
+		a.OnProgress(text)
+	}
+}
+
+// Ends a completed session in the same locked section that saw it complete,
+// so a Stop in between can't get it saved
+func (a *aimArena) finishLocked() aimSession {
+	session := a.session
+	if session.Targets > 0 {
+		session.TimeToTarget /= float64(session.Targets)
+		session.Efficiency = a.efficiencies / float64(session.Targets)
+	}
+	a.active = false
+	a.stopLocked()
+	a.circle.Hide()
+	return session
+}
+
+func (a *aimArena) finished(session aimSession) {
+	if a.OnFinished != nil {
+		a.OnFinished(session)
+	}
+}
+
+func showAimTestWindow() {
+	window := fyneApp.App.NewWindow("Aim test - " + windowTitle())
+	arena := newAimArena()
+	status := widget.NewLabel("Choose a mode and press Start")
+	summary := widget.NewLabel("")
+	summary.TextStyle = fyne.TextStyle{Monospace: true}
+	showSummary := func() {
+		sessions, err := loadAimSessions()
+		if err != nil {
+			summary.SetText(err.Error())
+			return
+		}
+		summary.SetText(aimSummaryTable(summarizeAimSessions(sessions)))
+	}
+	showSummary()
+
+	mode := widget.NewSelect(aimModes, nil)
+	mode.SetSelected(aimClick)
+	arena.OnProgress = status.SetText
+	arena.OnFinished = func(session aimSession) {
+		if err := saveAimSession(session); err != nil {
+			status.SetText(err.Error())
+			return
+		}
+		status.SetText(fmt.Sprintf("Session saved, %d targets", session.Targets))
+		if session.Mode == aimTracking {
+			status.SetText("Session saved, " + formatFloat(session.OnTarget*100, 1) + "% on target")
+		}
+		showSummary()
+	}
+	startBtn := widget.NewButton("Start", func() {
+		arena.Start(mode.Selected)
+		if mode.Selected == aimTracking {
+			status.SetText("Keep the cursor on the moving target")
+		} else {
+			status.SetText(fmt.Sprintf("Click the %d targets as fast as you can", aimTargets))
+		}
+	})
+	stopBtn := widget.NewButton("Stop", func() {
+		arena.Stop()
+		status.SetText("Session cancelled")
+	})
+
+	summaryScroll := container.NewScroll(summary)
+	summaryScroll.SetMinSize(fyne.NewSize(0, 120))
+	top := container.NewBorder(nil, nil, container.NewHBox(mode, startBtn, stopBtn), nil, status)
+	window.SetContent(container.NewBorder(top, summaryScroll, nil, nil, arena))
+	window.SetOnClosed(arena.Stop)
+	window.Resize(fyne.NewSize(900, 700))
+	window.Show()
+}
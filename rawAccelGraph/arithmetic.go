@@ -0,0 +1,187 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	arithmeticMultiply = "Multiply A × B"
+	arithmeticAdd      = "Add A + B"
+	arithmeticSubtract = "Subtract A - B"
+	arithmeticCompose  = "Compose B after A"
+	arithmeticAverage  = "Weighted average"
+
+	operandCurrent  = "(current curve)"
+	operandConstant = "(constant)"
+)
+
+var arithmeticOps = []string{arithmeticMultiply, arithmeticAdd, arithmeticSubtract, arithmeticCompose, arithmeticAverage}
+
+type weightedCurve struct {
+	Points []curvePoint
+	Weight float64
+}
+
+// Combine a and b on the grid of a
+func combineCurves(op string, a, b []curvePoint) []curvePoint {
+	result := make([]curvePoint, len(a))
+	for i, p := range a {
+		var y float64
+		switch op {
+		case arithmeticMultiply:
+			y = p.Y * interpolate(b, p.X)
+		case arithmeticAdd:
+			y = p.Y + interpolate(b, p.X)
+		case arithmeticSubtract:
+			y = p.Y - interpolate(b, p.X)
+		case arithmeticCompose:
+			// B sees the output speed of A, sensitivities multiply
+			y = p.Y * interpolate(b, p.X*p.Y)
+		}
+		result[i] = curvePoint{X: p.X, Y: y}
+	}
+	return result
+}
+
+// Weighted average on the grid of the first curve
+func averageCurves(curves []weightedCurve) ([]curvePoint, error) {
+	if len(curves) == 0 {
+		return nil, fmt.Errorf("no profile selected")
+	}
+	total := 0.0
+	for _, c := range curves {
+		total += c.Weight
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("weights sum to zero")
+	}
+	result := make([]curvePoint, len(curves[0].Points))
+	for i, p := range curves[0].Points {
+		y := 0.0
+		for _, c := range curves {
+			y += c.Weight * interpolate(c.Points, p.X)
+		}
+		result[i] = curvePoint{X: p.X, Y: y / total}
+	}
+	return result, nil
+}
+
+// Curve and settings of an operand from the dialog
+func operandConfig(name string, constant string) (Config, []curvePoint, error) {
+	switch name {
+	case operandCurrent:
+		cfg := currentConfig()
+		return cfg, currentCurve(), nil
+	case operandConstant:
+		value, err := strconv.ParseFloat(constant, 64)
+		if err != nil {
+			return Config{}, nil, err
+		}
+		// Two points are enough, interpolate is constant outside of the curve
+		return Config{}, []curvePoint{{X: 0, Y: value}, {X: 1, Y: value}}, nil
+	}
+	cfg, err := readConfig(name)
+	if err != nil {
+		return cfg, nil, err
+	}
+	return cfg, configCurve(cfg), nil
+}
+
+func showArithmeticDialog() {
+	profiles := append([]string{operandCurrent}, listConfigs()...)
+	op := widget.NewSelect(arithmeticOps, nil)
+	a := widget.NewSelect(profiles, nil)
+	a.SetSelected(operandCurrent)
+	b := widget.NewSelect(append([]string{operandConstant}, profiles...), nil)
+	b.SetSelected(operandConstant)
+	constant := widget.NewEntry()
+	constant.Text = "1"
+
+	// One weight per stored profile for the weighted average, 0 skips it
+	weights := map[string]*widget.Entry{}
+	weightItems := container.NewVBox()
+	for _, name := range profiles {
+		entry := widget.NewEntry()
+		entry.Text = "0"
+		weights[name] = entry
+		weightItems.Add(container.NewBorder(nil, nil, widget.NewLabel(name), nil, entry))
+	}
+	weightScroll := container.NewVScroll(weightItems)
+	weightScroll.SetMinSize(fyne.NewSize(300, 150))
+
+	pairItems := container.NewVBox(
+		widget.NewForm(
+			widget.NewFormItem("A", a),
+			widget.NewFormItem("B", b),
+			widget.NewFormItem("Constant", constant),
+		),
+	)
+	op.OnChanged = func(s string) {
+		if s == arithmeticAverage {
+			pairItems.Hide()
+			weightScroll.Show()
+		} else {
+			weightScroll.Hide()
+			pairItems.Show()
+		}
+	}
+	op.SetSelected(arithmeticMultiply)
+
+	content := container.NewVBox(op, pairItems, weightScroll)
+	arithmeticDial := dialog.NewCustomConfirm("Combine profiles", "Open result", "Cancel", content, func(ok bool) {
+		if !ok {
+			return
+		}
+		if op.Selected == arithmeticAverage {
+			var base Config
+			var curves []weightedCurve
+			for _, name := range profiles {
+				weight, err := strconv.ParseFloat(weights[name].Text, 64)
+				if err != nil {
+					errorDialog(fmt.Errorf("weight of %s: %w", name, err))
+					return
+				}
+				if weight == 0 {
+					continue
+				}
+				cfg, points, err := operandConfig(name, "")
+				if err != nil {
+					errorDialog(err)
+					return
+				}
+				if len(curves) == 0 {
+					base = cfg
+				}
+				curves = append(curves, weightedCurve{Points: points, Weight: weight})
+			}
+			result, err := averageCurves(curves)
+			if err != nil {
+				errorDialog(err)
+				return
+			}
+			openProfile(curveConfig(base, result), "weighted average (unsaved)")
+			return
+		}
+
+		cfgA, pointsA, err := operandConfig(a.Selected, constant.Text)
+		if err != nil {
+			errorDialog(err)
+			return
+		}
+		_, pointsB, err := operandConfig(b.Selected, constant.Text)
+		if err != nil {
+			errorDialog(err)
+			return
+		}
+		result := combineCurves(op.Selected, pointsA, pointsB)
+		openProfile(curveConfig(cfgA, result), op.Selected+" (unsaved)")
+	}, fyneApp.Window)
+	arithmeticDial.Resize(fyne.NewSize(450, 400))
+	arithmeticDial.Show()
+}
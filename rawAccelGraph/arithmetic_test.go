@@ -0,0 +1,68 @@
+package main
+
+import "testing"
+
+func TestCombineCurves(t *testing.T) {
+	a := []curvePoint{{X: 1, Y: 1}, {X: 10, Y: 2}}
+	b := []curvePoint{{X: 1, Y: 1.5}, {X: 20, Y: 3}}
+	tests := []struct {
+		op   string
+		want []float64
+	}{
+		{arithmeticMultiply, []float64{1.5, 2 * (1.5 + 1.5*9/19)}},
+		{arithmeticAdd, []float64{2.5, 2 + 1.5 + 1.5*9/19}},
+		{arithmeticSubtract, []float64{-0.5, 2 - 1.5 - 1.5*9/19}},
+		// B sees the output speed of A: 1 and 20
+		{arithmeticCompose, []float64{1.5, 2 * 3}},
+	}
+	for _, test := range tests {
+		got := combineCurves(test.op, a, b)
+		for i := range test.want {
+			if got[i].X != a[i].X || !almostEqual(got[i].Y, test.want[i]) {
+				t.Errorf("%s: point %d = %v, want {%v %v}", test.op, i, got[i], a[i].X, test.want[i])
+			}
+		}
+	}
+}
+
+func TestAverageCurves(t *testing.T) {
+	a := []curvePoint{{X: 1, Y: 1}, {X: 10, Y: 2}}
+	b := []curvePoint{{X: 1, Y: 2}, {X: 5, Y: 4}}
+	got, err := averageCurves([]weightedCurve{{Points: a, Weight: 3}, {Points: b, Weight: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// b holds 4 past its last point
+	want := []float64{(3*1 + 2) / 4.0, (3*2 + 4) / 4.0}
+	for i := range want {
+		if !almostEqual(got[i].Y, want[i]) {
+			t.Errorf("point %d = %v, want %v", i, got[i].Y, want[i])
+		}
+	}
+
+	if _, err := averageCurves(nil); err == nil {
+		t.Error("no error without curves")
+	}
+	if _, err := averageCurves([]weightedCurve{{Points: a, Weight: 1}, {Points: b, Weight: -1}}); err == nil {
+		t.Error("no error for weights summing to zero")
+	}
+}
+
+func TestCurveConfigKeepsLowestPoint(t *testing.T) {
+	base := Config{ConfAbcisses: "30", ConfCollumns: "3", ConfOrdonneesMin: "1", ConfOrdonneesMax: "3"}
+	points := []curvePoint{{X: 1, Y: 0.02356}, {X: 11, Y: 0.5}, {X: 21, Y: 1}, {X: 31, Y: 4.2}}
+	cfg := curveConfig(base, points)
+	if cfg.ConfOrdonneesMax != "5" {
+		t.Errorf("Ratio Max %s, want 5", cfg.ConfOrdonneesMax)
+	}
+	// Only the points at Ratio Min are left out of the table, none of these
+	if table := configTable(cfg); len(table) != len(points) {
+		t.Errorf("Ratio Min %s, table %v misses points of %v", cfg.ConfOrdonneesMin, table, points)
+	}
+
+	// A curve inside the range keeps it
+	cfg = curveConfig(base, []curvePoint{{X: 1, Y: 1}, {X: 11, Y: 2}})
+	if cfg.ConfOrdonneesMin != "1" || cfg.ConfOrdonneesMax != "3" {
+		t.Errorf("range %s-%s, want 1-3", cfg.ConfOrdonneesMin, cfg.ConfOrdonneesMax)
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Local, user editable list of degrees per count at in-game sensitivity 1
+const yawFile = "yaw.yml"
+
+var defaultYaws = map[string]float64{
+	"Quake":     0.022,
+	"Source":    0.022,
+	"Apex":      0.022,
+	"Overwatch": 0.0066,
+	"Valorant":  0.07,
+	"Fortnite":  0.005555,
+}
+
+// The file is only written once the user saves a yaw, until then the
+// defaults are used
+func loadYaws() (map[string]float64, error) {
+	yaws := map[string]float64{}
+	data, err := ioutil.ReadFile(yawFile)
+	if os.IsNotExist(err) {
+		for game, yaw := range defaultYaws {
+			yaws[game] = yaw
+		}
+		return yaws, nil
+	}
+	if err != nil {
+		return yaws, err
+	}
+	return yaws, yaml.Unmarshal(data, &yaws)
+}
+
+func saveYaws(yaws map[string]float64) error {
+	data, err := yaml.Marshal(yaws)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(yawFile, data, 0644)
+}
+
+// Hand movement for a full turn when moving at a constant input speed
+func cm360(dpi, yaw, sens, accelSens float64) float64 {
+	degreesPerCount := yaw * sens * accelSens
+	if degreesPerCount <= 0 || dpi <= 0 {
+		return 0
+	}
+	return 360 / degreesPerCount / dpi * 2.54
+}
+
+func cm360Table(points []curvePoint, dpi, yaw, sens float64) string {
+	var lines []string
+	lines = append(lines, fmt.Sprintf("%-12s %-8s %-8s", "Speed "+displayedUnit, "Sens", "cm/360"))
+	for _, p := range points {
+		lines = append(lines, fmt.Sprintf("%-12s %-8s %-8s",
+			formatSpeed(p.X, displayedUnit, dpi), formatFloat(p.Y, 3), formatFloat(cm360(dpi, yaw, sens, p.Y), 2)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func showCalculatorDialog() {
+	yaws, err := loadYaws()
+	errorDialog(err)
+	var games []string
+	for game := range yaws {
+		games = append(games, game)
+	}
+	sort.Strings(games)
+
+	dpi := widget.NewEntry()
+	dpi.Text = formatFloat(settingsDPI(), 0)
+	yaw := widget.NewEntry()
+	sens := widget.NewEntry()
+	sens.Text = "1"
+	speed := widget.NewEntry()
+	speed.Text = "0"
+	result := widget.NewLabel("")
+	table := widget.NewLabel("")
+	table.TextStyle = fyne.TextStyle{Monospace: true}
+
+	game := widget.NewSelectEntry(games)
+	update := func() {
+		d, err1 := strconv.ParseFloat(dpi.Text, 64)
+		y, err2 := strconv.ParseFloat(yaw.Text, 64)
+		s, err3 := strconv.ParseFloat(sens.Text, 64)
+		v, err4 := strconv.ParseFloat(speed.Text, 64)
+		if err1 != nil || err2 != nil || err3 != nil || err4 != nil {
+			result.SetText("Invalid value")
+			return
+		}
+		points := currentCurve()
+		counts := v / unitFactor(displayedUnit, d)
+		accelSens := interpolate(points, counts)
+		result.SetText(fmt.Sprintf("Effective sens %s, %s cm/360", formatFloat(s*accelSens, 4), formatFloat(cm360(d, y, s, accelSens), 2)))
+		table.SetText(cm360Table(points, d, y, s))
+	}
+	game.OnChanged = func(s string) {
+		if value, ok := yaws[s]; ok {
+			yaw.SetText(strconv.FormatFloat(value, 'f', -1, 64))
+		}
+	}
+	for _, entry := range []*widget.Entry{dpi, yaw, sens, speed} {
+		entry.OnChanged = func(string) { update() }
+	}
+	if len(games) > 0 {
+		game.SetText(games[0])
+	}
+	update()
+
+	saveYaw := widget.NewButton("Save yaw for this game", func() {
+		value, err := strconv.ParseFloat(yaw.Text, 64)
+		if err != nil || game.Text == "" {
+			errorDialog(fmt.Errorf("a game name and a numeric yaw are needed"))
+			return
+		}
+		yaws[game.Text] = value
+		errorDialog(saveYaws(yaws))
+	})
+
+	form := widget.NewForm(
+		widget.NewFormItem("Game", game),
+		widget.NewFormItem("Yaw (deg/count)", container.NewBorder(nil, nil, nil, saveYaw, yaw)),
+		widget.NewFormItem("In-game sens", sens),
+		widget.NewFormItem("DPI", dpi),
+		widget.NewFormItem("Input speed ("+displayedUnit+")", speed),
+	)
+	tableScroll := container.NewVScroll(table)
+	tableScroll.SetMinSize(fyne.NewSize(350, 250))
+	content := container.NewVBox(form, result, tableScroll)
+	calcDial := dialog.NewCustom("cm/360 calculator", "Close", content, fyneApp.Window)
+	calcDial.Resize(fyne.NewSize(500, 550))
+	calcDial.Show()
+}
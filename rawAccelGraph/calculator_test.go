@@ -0,0 +1,84 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCm360(t *testing.T) {
+	tests := []struct {
+		dpi, yaw, sens, accel float64
+		want                  float64
+	}{
+		// Quake at 800 DPI and sensitivity 1
+		{800, 0.022, 1, 1, 360 / 0.022 / 800 * 2.54},
+		// Doubling the DPI, the sensitivity or the acceleration halves the distance
+		{1600, 0.022, 1, 1, 360 / 0.022 / 1600 * 2.54},
+		{800, 0.022, 2, 1, 360 / 0.022 / 1600 * 2.54},
+		{800, 0.022, 1, 2, 360 / 0.022 / 1600 * 2.54},
+		{400, 0.07, 0.5, 1.5, 360 / (0.07 * 0.75) / 400 * 2.54},
+		{0, 0.022, 1, 1, 0},
+		{800, 0, 1, 1, 0},
+		{800, 0.022, 1, -1, 0},
+	}
+	for _, test := range tests {
+		if got := cm360(test.dpi, test.yaw, test.sens, test.accel); !almostEqual(got, test.want) {
+			t.Errorf("cm360(%v, %v, %v, %v) = %v, want %v", test.dpi, test.yaw, test.sens, test.accel, got, test.want)
+		}
+	}
+}
+
+func TestCm360Table(t *testing.T) {
+	defer func(unit string) { displayedUnit = unit }(displayedUnit)
+	displayedUnit = unitCountsMs
+	got := cm360Table([]curvePoint{{X: 1, Y: 1}, {X: 20, Y: 2}}, 800, 0.022, 1)
+	lines := strings.Split(got, "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %q, want a header and 2 lines", got)
+	}
+	for i, want := range [][]string{{"1", "1.000", "51.95"}, {"20", "2.000", "25.98"}} {
+		if fields := strings.Fields(lines[i+1]); strings.Join(fields, " ") != strings.Join(want, " ") {
+			t.Errorf("line %d = %q, want %v", i+1, lines[i+1], want)
+		}
+	}
+}
+
+func TestLoadYawsDoesNotWrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "yaw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	wd, _ := os.Getwd()
+	defer os.Chdir(wd)
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	yaws, err := loadYaws()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if yaws["Quake"] != 0.022 {
+		t.Errorf("Quake yaw %v, want the default 0.022", yaws["Quake"])
+	}
+	if _, err := os.Stat(yawFile); !os.IsNotExist(err) {
+		t.Errorf("%s written by loading: %v", yawFile, err)
+	}
+	// Editing the loaded map leaves the defaults alone
+	yaws["Quake"] = 1
+	if defaultYaws["Quake"] != 0.022 {
+		t.Error("defaults changed")
+	}
+
+	// Saving an edit writes the whole list
+	if err := saveYaws(yaws); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := loadYaws()
+	if err != nil || saved["Quake"] != 1 || saved["Valorant"] != 0.07 {
+		t.Errorf("saved yaws %v, %v", saved, err)
+	}
+}
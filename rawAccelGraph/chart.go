@@ -0,0 +1,206 @@
+package main
+
+import (
+	"image/color"
+	"math"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+var overlayColor = color.NRGBA{R: 0xff, G: 0x98, B: 0x00, A: 0xff}
+var selectionColor = color.NRGBA{R: 0x80, G: 0x80, B: 0x80, A: 0x40}
+var yAxisColor = color.NRGBA{R: 0x00, G: 0xbc, B: 0xd4, A: 0xff}
+var histogramColor = color.NRGBA{R: 0x4c, G: 0xaf, B: 0x50, A: 0x50}
+
+type chartSeries struct {
+	Points []curvePoint
+	Color  color.Color
+	Width  float32
+}
+
+// Simple line chart used for previews and overlays
+type curveChart struct {
+	widget.BaseWidget
+	Series []chartSeries
+
+	// Abscissa range highlighted on the chart, From == To means no selection
+	SelectFrom float64
+	SelectTo   float64
+	OnSelected func(from, to float64)
+
+	dragStart float32
+	dragging  bool
+}
+
+func newCurveChart(series ...chartSeries) *curveChart {
+	chart := &curveChart{Series: series}
+	chart.ExtendBaseWidget(chart)
+	return chart
+}
+
+func (c *curveChart) SetSeries(series ...chartSeries) {
+	c.Series = series
+	c.Refresh()
+}
+
+func (c *curveChart) SetSelection(from, to float64) {
+	c.SelectFrom, c.SelectTo = math.Min(from, to), math.Max(from, to)
+	c.Refresh()
+}
+
+// Dragging horizontally selects a range of abscissas
+func (c *curveChart) Dragged(e *fyne.DragEvent) {
+	if c.OnSelected == nil {
+		return
+	}
+	if !c.dragging {
+		c.dragging = true
+		c.dragStart = e.Position.X - e.Dragged.DX
+	}
+	c.SetSelection(c.toX(c.dragStart), c.toX(e.Position.X))
+}
+
+func (c *curveChart) DragEnd() {
+	if !c.dragging {
+		return
+	}
+	c.dragging = false
+	c.OnSelected(c.SelectFrom, c.SelectTo)
+}
+
+func (c *curveChart) padding() float32 {
+	return float32(theme.Padding() * 2)
+}
+
+func (c *curveChart) toPos(p curvePoint, size fyne.Size) fyne.Position {
+	minX, maxX, minY, maxY := c.bounds()
+	pad := c.padding()
+	width := size.Width - 2*pad
+	height := size.Height - 2*pad
+	x := pad + float32((p.X-minX)/(maxX-minX))*width
+	y := pad + height - float32((p.Y-minY)/(maxY-minY))*height
+	return fyne.NewPos(x, y)
+}
+
+func (c *curveChart) toX(pos float32) float64 {
+	minX, maxX, _, _ := c.bounds()
+	pad := c.padding()
+	width := c.Size().Width - 2*pad
+	if width <= 0 {
+		return minX
+	}
+	ratio := math.Max(0, math.Min(1, float64((pos-pad)/width)))
+	return minX + ratio*(maxX-minX)
+}
+
+func (c *curveChart) CreateRenderer() fyne.WidgetRenderer {
+	r := &curveChartRenderer{
+		chart:      c,
+		background: canvas.NewRectangle(theme.InputBackgroundColor()),
+		selection:  canvas.NewRectangle(selectionColor),
+	}
+	r.Refresh()
+	return r
+}
+
+type curveChartRenderer struct {
+	chart      *curveChart
+	background *canvas.Rectangle
+	selection  *canvas.Rectangle
+	lines      [][]*canvas.Line
+	minLabel   *canvas.Text
+	maxLabel   *canvas.Text
+}
+
+func (r *curveChartRenderer) Destroy() {}
+
+func (r *curveChartRenderer) MinSize() fyne.Size {
+	return fyne.NewSize(300, 200)
+}
+
+func (r *curveChartRenderer) Objects() []fyne.CanvasObject {
+	objects := []fyne.CanvasObject{r.background, r.selection}
+	for _, serie := range r.lines {
+		for _, line := range serie {
+			objects = append(objects, line)
+		}
+	}
+	if r.minLabel != nil {
+		objects = append(objects, r.minLabel, r.maxLabel)
+	}
+	return objects
+}
+
+func (r *curveChartRenderer) Refresh() {
+	r.lines = nil
+	for _, serie := range r.chart.Series {
+		var lines []*canvas.Line
+		for i := 1; i < len(serie.Points); i++ {
+			line := canvas.NewLine(serie.Color)
+			line.StrokeWidth = serie.Width
+			lines = append(lines, line)
+		}
+		r.lines = append(r.lines, lines)
+	}
+	_, _, minY, maxY := r.chart.bounds()
+	r.minLabel = canvas.NewText(formatFloat(minY, 3), theme.TextColor())
+	r.minLabel.TextSize = 10
+	r.maxLabel = canvas.NewText(formatFloat(maxY, 3), theme.TextColor())
+	r.maxLabel.TextSize = 10
+	r.background.FillColor = theme.InputBackgroundColor()
+	r.Layout(r.chart.Size())
+	canvas.Refresh(r.chart)
+}
+
+func (r *curveChartRenderer) Layout(size fyne.Size) {
+	r.background.Resize(size)
+	for s, serie := range r.chart.Series {
+		if s >= len(r.lines) {
+			break
+		}
+		for i, line := range r.lines[s] {
+			line.Position1 = r.chart.toPos(serie.Points[i], size)
+			line.Position2 = r.chart.toPos(serie.Points[i+1], size)
+		}
+	}
+	r.selection.Hidden = r.chart.SelectFrom == r.chart.SelectTo
+	if !r.selection.Hidden {
+		from := r.chart.toPos(curvePoint{X: r.chart.SelectFrom}, size)
+		to := r.chart.toPos(curvePoint{X: r.chart.SelectTo}, size)
+		r.selection.Move(fyne.NewPos(from.X, 0))
+		r.selection.Resize(fyne.NewSize(to.X-from.X, size.Height))
+	}
+	if r.minLabel != nil {
+		r.maxLabel.Move(fyne.NewPos(2, 0))
+		r.minLabel.Move(fyne.NewPos(2, size.Height-r.minLabel.MinSize().Height))
+	}
+}
+
+// Common bounds of all the series, never empty
+func (c *curveChart) bounds() (float64, float64, float64, float64) {
+	var all []curvePoint
+	for _, serie := range c.Series {
+		all = append(all, serie.Points...)
+	}
+	return curveBounds(all)
+}
+
+// Redraw the main chart from the sliders and the current overlays
+func refreshChart() {
+	if ui.Chart == nil {
+		return
+	}
+	series := []chartSeries{{Points: currentCurve(), Color: theme.PrimaryColor(), Width: 2}}
+	if profileSettings.SeparateXY {
+		series = append(series, chartSeries{Points: currentCurveY(), Color: yAxisColor, Width: 2})
+	}
+	ui.Chart.SetSeries(append(series, ui.Overlays...)...)
+}
+
+func setOverlay(series ...chartSeries) {
+	ui.Overlays = series
+	refreshChart()
+}
@@ -0,0 +1,373 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	imageFormatSVG = "svg"
+	imageFormatPNG = "png"
+)
+
+// Headless rendering of a curve, used by the UI and by the CLI
+type chartImage struct {
+	Points []curvePoint
+	// Vertical curve, only drawn when the profile has a separate one
+	PointsY []curvePoint
+	Title   string
+	// Input speed unit and the DPI it is converted with, counts/ms when empty
+	Unit     string
+	DPI      float64
+	Velocity bool
+	Gain     bool
+	Grid     bool
+	Labels   bool
+	Width    int
+	Height   int
+}
+
+type imageSeries struct {
+	Name   string
+	Points []curvePoint
+	Color  color.NRGBA
+	Width  float64
+}
+
+var (
+	imageBackground = color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	imageAxis       = color.NRGBA{R: 0x30, G: 0x30, B: 0x30, A: 0xff}
+	imageGrid       = color.NRGBA{R: 0xdd, G: 0xdd, B: 0xdd, A: 0xff}
+	imageSens       = color.NRGBA{R: 0x21, G: 0x96, B: 0xf3, A: 0xff}
+	imageVelocity   = color.NRGBA{R: 0x4c, G: 0xaf, B: 0x50, A: 0xff}
+	imageGain       = color.NRGBA{R: 0xff, G: 0x98, B: 0x00, A: 0xff}
+	imageSensY      = color.NRGBA{R: 0x00, G: 0xbc, B: 0xd4, A: 0xff}
+)
+
+func velocityCurve(points []curvePoint) []curvePoint {
+	result := make([]curvePoint, len(points))
+	for i, p := range points {
+		result[i] = curvePoint{X: p.X, Y: p.X * p.Y}
+	}
+	return result
+}
+
+// Slope of the output velocity, placed in the middle of each segment
+func gainCurve(points []curvePoint) []curvePoint {
+	var result []curvePoint
+	for i := 1; i < len(points); i++ {
+		a, b := points[i-1], points[i]
+		if b.X == a.X {
+			continue
+		}
+		result = append(result, curvePoint{X: (a.X + b.X) / 2, Y: (b.X*b.Y - a.X*a.Y) / (b.X - a.X)})
+	}
+	return result
+}
+
+func curveBounds(points []curvePoint) (float64, float64, float64, float64) {
+	minX, maxX := math.Inf(1), math.Inf(-1)
+	minY, maxY := math.Inf(1), math.Inf(-1)
+	for _, p := range points {
+		minX, maxX = math.Min(minX, p.X), math.Max(maxX, p.X)
+		minY, maxY = math.Min(minY, p.Y), math.Max(maxY, p.Y)
+	}
+	if math.IsInf(minX, 0) {
+		return 0, 1, 0, 1
+	}
+	if maxX == minX {
+		maxX = minX + 1
+	}
+	if maxY == minY {
+		maxY = minY + 1
+	}
+	return minX, maxX, minY, maxY
+}
+
+// Overlays are rescaled to the range of the sensitivity curve
+func rescale(points []curvePoint, minY, maxY float64) []curvePoint {
+	_, _, lo, hi := curveBounds(points)
+	result := make([]curvePoint, len(points))
+	for i, p := range points {
+		result[i] = curvePoint{X: p.X, Y: minY + (p.Y-lo)/(hi-lo)*(maxY-minY)}
+	}
+	return result
+}
+
+// Both curves share the axes
+func (c chartImage) bounds() (float64, float64, float64, float64) {
+	return curveBounds(append(append([]curvePoint{}, c.Points...), c.PointsY...))
+}
+
+func (c chartImage) unit() string {
+	if c.Unit == "" {
+		return unitCountsMs
+	}
+	return c.Unit
+}
+
+func (c chartImage) speedLabel(x float64) string {
+	return formatSpeed(x, c.unit(), c.DPI)
+}
+
+func (c chartImage) axisLabel() string {
+	return "input speed (" + c.unit() + ")"
+}
+
+func (c chartImage) series() []imageSeries {
+	_, _, minY, maxY := c.bounds()
+	series := []imageSeries{{Name: "sensitivity", Points: c.Points, Color: imageSens, Width: 2}}
+	if len(c.PointsY) > 0 {
+		series[0].Name = "sensitivity X"
+		series = append(series, imageSeries{Name: "sensitivity Y", Points: c.PointsY, Color: imageSensY, Width: 2})
+	}
+	if c.Velocity {
+		series = append(series, imageSeries{Name: "velocity (scaled)", Points: rescale(velocityCurve(c.Points), minY, maxY), Color: imageVelocity, Width: 1.5})
+	}
+	if c.Gain {
+		series = append(series, imageSeries{Name: "gain (scaled)", Points: rescale(gainCurve(c.Points), minY, maxY), Color: imageGain, Width: 1.5})
+	}
+	return series
+}
+
+// Plot area inside the margins and the mapping from curve to pixels
+func (c chartImage) layout() (image.Rectangle, func(curvePoint) (float64, float64)) {
+	left, right, top, bottom := 20, 20, 20, 20
+	if c.Labels {
+		left, bottom = 55, 35
+	}
+	if c.Title != "" {
+		top = 35
+	}
+	area := image.Rect(left, top, c.Width-right, c.Height-bottom)
+	minX, maxX, minY, maxY := c.bounds()
+	return area, func(p curvePoint) (float64, float64) {
+		x := float64(area.Min.X) + (p.X-minX)/(maxX-minX)*float64(area.Dx())
+		y := float64(area.Max.Y) - (p.Y-minY)/(maxY-minY)*float64(area.Dy())
+		return x, y
+	}
+}
+
+// Evenly spaced tick values on both axes
+func (c chartImage) ticks() ([]float64, []float64) {
+	minX, maxX, minY, maxY := c.bounds()
+	var xs, ys []float64
+	for i := 0; i <= 5; i++ {
+		xs = append(xs, minX+(maxX-minX)*float64(i)/5)
+		ys = append(ys, minY+(maxY-minY)*float64(i)/5)
+	}
+	return xs, ys
+}
+
+func svgColor(c color.NRGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+func (c chartImage) SVG() string {
+	var sb strings.Builder
+	area, toPixel := c.layout()
+	xs, ys := c.ticks()
+	minX, _, minY, _ := c.bounds()
+
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", c.Width, c.Height, c.Width, c.Height)
+	fmt.Fprintf(&sb, `<rect width="100%%" height="100%%" fill="%s"/>`+"\n", svgColor(imageBackground))
+	if c.Grid {
+		for _, x := range xs {
+			px, _ := toPixel(curvePoint{X: x, Y: minY})
+			fmt.Fprintf(&sb, `<line x1="%.1f" y1="%d" x2="%.1f" y2="%d" stroke="%s"/>`+"\n", px, area.Min.Y, px, area.Max.Y, svgColor(imageGrid))
+		}
+		for _, y := range ys {
+			_, py := toPixel(curvePoint{X: minX, Y: y})
+			fmt.Fprintf(&sb, `<line x1="%d" y1="%.1f" x2="%d" y2="%.1f" stroke="%s"/>`+"\n", area.Min.X, py, area.Max.X, py, svgColor(imageGrid))
+		}
+	}
+	fmt.Fprintf(&sb, `<polyline points="%d,%d %d,%d %d,%d" fill="none" stroke="%s"/>`+"\n",
+		area.Min.X, area.Min.Y, area.Min.X, area.Max.Y, area.Max.X, area.Max.Y, svgColor(imageAxis))
+	for _, s := range c.series() {
+		var coords []string
+		for _, p := range s.Points {
+			x, y := toPixel(p)
+			coords = append(coords, fmt.Sprintf("%.1f,%.1f", x, y))
+		}
+		fmt.Fprintf(&sb, `<polyline points="%s" fill="none" stroke="%s" stroke-width="%.1f"/>`+"\n", strings.Join(coords, " "), svgColor(s.Color), s.Width)
+	}
+	if c.Labels {
+		for _, x := range xs {
+			px, _ := toPixel(curvePoint{X: x, Y: minY})
+			fmt.Fprintf(&sb, `<text x="%.1f" y="%d" font-family="sans-serif" font-size="11" text-anchor="middle">%s</text>`+"\n", px, area.Max.Y+15, c.speedLabel(x))
+		}
+		for _, y := range ys {
+			_, py := toPixel(curvePoint{X: minX, Y: y})
+			fmt.Fprintf(&sb, `<text x="%d" y="%.1f" font-family="sans-serif" font-size="11" text-anchor="end">%s</text>`+"\n", area.Min.X-5, py+4, formatFloat(y, 2))
+		}
+		fmt.Fprintf(&sb, `<text x="%d" y="%d" font-family="sans-serif" font-size="11" text-anchor="middle">%s</text>`+"\n", (area.Min.X+area.Max.X)/2, c.Height-5, c.axisLabel())
+		for i, s := range c.series() {
+			fmt.Fprintf(&sb, `<text x="%d" y="%d" font-family="sans-serif" font-size="11" text-anchor="end" fill="%s">%s</text>`+"\n", area.Max.X-5, area.Min.Y+15+14*i, svgColor(s.Color), s.Name)
+		}
+	}
+	if c.Title != "" {
+		fmt.Fprintf(&sb, `<text x="%d" y="22" font-family="sans-serif" font-size="15" text-anchor="middle">%s</text>`+"\n", c.Width/2, svgEscape(c.Title))
+	}
+	sb.WriteString("</svg>\n")
+	return sb.String()
+}
+
+func svgEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;").Replace(s)
+}
+
+func (c chartImage) PNG(w io.Writer) error {
+	img := image.NewNRGBA(image.Rect(0, 0, c.Width, c.Height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(imageBackground), image.Point{}, draw.Src)
+	area, toPixel := c.layout()
+	xs, ys := c.ticks()
+	minX, _, minY, _ := c.bounds()
+
+	if c.Grid {
+		for _, x := range xs {
+			px, _ := toPixel(curvePoint{X: x, Y: minY})
+			drawLine(img, px, float64(area.Min.Y), px, float64(area.Max.Y), 1, imageGrid)
+		}
+		for _, y := range ys {
+			_, py := toPixel(curvePoint{X: minX, Y: y})
+			drawLine(img, float64(area.Min.X), py, float64(area.Max.X), py, 1, imageGrid)
+		}
+	}
+	drawLine(img, float64(area.Min.X), float64(area.Min.Y), float64(area.Min.X), float64(area.Max.Y), 1, imageAxis)
+	drawLine(img, float64(area.Min.X), float64(area.Max.Y), float64(area.Max.X), float64(area.Max.Y), 1, imageAxis)
+	for _, s := range c.series() {
+		for i := 1; i < len(s.Points); i++ {
+			x1, y1 := toPixel(s.Points[i-1])
+			x2, y2 := toPixel(s.Points[i])
+			drawLine(img, x1, y1, x2, y2, s.Width, s.Color)
+		}
+	}
+	if c.Labels {
+		for _, x := range xs {
+			px, _ := toPixel(curvePoint{X: x, Y: minY})
+			label := c.speedLabel(x)
+			drawText(img, int(px)-textWidth(label)/2, area.Max.Y+16, label, imageAxis)
+		}
+		for _, y := range ys {
+			_, py := toPixel(curvePoint{X: minX, Y: y})
+			label := formatFloat(y, 2)
+			drawText(img, area.Min.X-5-textWidth(label), int(py)+4, label, imageAxis)
+		}
+		label := c.axisLabel()
+		drawText(img, (area.Min.X+area.Max.X-textWidth(label))/2, c.Height-6, label, imageAxis)
+		for i, s := range c.series() {
+			drawText(img, area.Max.X-5-textWidth(s.Name), area.Min.Y+15+14*i, s.Name, s.Color)
+		}
+	}
+	if c.Title != "" {
+		drawText(img, (c.Width-textWidth(c.Title))/2, 22, c.Title, imageAxis)
+	}
+	return png.Encode(w, img)
+}
+
+// Stamp a disc of the stroke width every half pixel along the segment
+func drawLine(img *image.NRGBA, x1, y1, x2, y2, width float64, col color.NRGBA) {
+	length := math.Hypot(x2-x1, y2-y1)
+	steps := int(math.Ceil(length*2)) + 1
+	radius := width / 2
+	for i := 0; i <= steps; i++ {
+		t := float64(i) / float64(steps)
+		cx, cy := x1+(x2-x1)*t, y1+(y2-y1)*t
+		for py := int(math.Floor(cy - radius)); py <= int(math.Ceil(cy+radius)); py++ {
+			for px := int(math.Floor(cx - radius)); px <= int(math.Ceil(cx+radius)); px++ {
+				if math.Hypot(float64(px)+0.5-cx, float64(py)+0.5-cy) <= math.Max(radius, 0.5) {
+					img.SetNRGBA(px, py, col)
+				}
+			}
+		}
+	}
+}
+
+func drawText(img *image.NRGBA, x, y int, text string, col color.NRGBA) {
+	d := &font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(col),
+		Face: basicfont.Face7x13,
+		Dot:  fixed.P(x, y),
+	}
+	d.DrawString(text)
+}
+
+func textWidth(text string) int {
+	return font.MeasureString(basicfont.Face7x13, text).Ceil()
+}
+
+func writeChartImage(w io.Writer, chart chartImage, format string) error {
+	switch format {
+	case imageFormatSVG:
+		_, err := io.WriteString(w, chart.SVG())
+		return err
+	case imageFormatPNG:
+		return chart.PNG(w)
+	}
+	return fmt.Errorf("unknown image format %q", format)
+}
+
+func showExportImageDialog() {
+	format := widget.NewSelect([]string{imageFormatSVG, imageFormatPNG}, nil)
+	format.SetSelected(imageFormatSVG)
+	title := widget.NewEntry()
+	title.Text = currentProfile
+	velocity := widget.NewCheck("Velocity overlay", nil)
+	gain := widget.NewCheck("Gain overlay", nil)
+	grid := widget.NewCheck("Grid", nil)
+	grid.Checked = true
+	labels := widget.NewCheck("Labels", nil)
+	labels.Checked = true
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Format", format),
+		widget.NewFormItem("Title", title),
+		widget.NewFormItem("", velocity),
+		widget.NewFormItem("", gain),
+		widget.NewFormItem("", grid),
+		widget.NewFormItem("", labels),
+	}
+	dialog.ShowForm("Export image", "Export", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		chart := chartImage{
+			Points:   currentCurve(),
+			Title:    title.Text,
+			Unit:     displayedUnit,
+			DPI:      settingsDPI(),
+			Velocity: velocity.Checked,
+			Gain:     gain.Checked,
+			Grid:     grid.Checked,
+			Labels:   labels.Checked,
+			Width:    800,
+			Height:   500,
+		}
+		if profileSettings.SeparateXY {
+			chart.PointsY = currentCurveY()
+		}
+		dialog.ShowFileSave(func(writer fyne.URIWriteCloser, err error) {
+			if err != nil || writer == nil {
+				errorDialog(err)
+				return
+			}
+			defer writer.Close()
+			errorDialog(writeChartImage(writer, chart, format.Selected))
+		}, fyneApp.Window)
+	}, fyneApp.Window)
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func testChart() chartImage {
+	return chartImage{
+		Points:   []curvePoint{{X: 0, Y: 1}, {X: 40, Y: 1.5}, {X: 80, Y: 2}},
+		Title:    "fast & <flat>",
+		Velocity: true,
+		Gain:     true,
+		Grid:     true,
+		Labels:   true,
+		Width:    400,
+		Height:   250,
+	}
+}
+
+func TestChartSVG(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeChartImage(&buf, testChart(), imageFormatSVG); err != nil {
+		t.Fatal(err)
+	}
+	var svg struct {
+		XMLName   xml.Name `xml:"svg"`
+		Width     int      `xml:"width,attr"`
+		Height    int      `xml:"height,attr"`
+		Polylines []struct {
+			Points string `xml:"points,attr"`
+			Stroke string `xml:"stroke,attr"`
+		} `xml:"polyline"`
+		Texts []string `xml:"text"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &svg); err != nil {
+		t.Fatalf("invalid svg: %v", err)
+	}
+	if svg.Width != 400 || svg.Height != 250 {
+		t.Errorf("size %dx%d, want 400x250", svg.Width, svg.Height)
+	}
+	// Axes, sensitivity, velocity and gain
+	if len(svg.Polylines) != 4 {
+		t.Fatalf("%d polylines, want 4", len(svg.Polylines))
+	}
+	if got := len(strings.Fields(svg.Polylines[1].Points)); got != 3 {
+		t.Errorf("sensitivity has %d points, want 3", got)
+	}
+	if got := len(strings.Fields(svg.Polylines[3].Points)); got != 2 {
+		t.Errorf("gain has %d points, want 2", got)
+	}
+	texts := strings.Join(svg.Texts, "|")
+	for _, want := range []string{"input speed (counts/ms)", "fast & <flat>", "sensitivity", "80"} {
+		if !strings.Contains(texts, want) {
+			t.Errorf("no text %q in %q", want, texts)
+		}
+	}
+}
+
+func TestChartSVGUnitAndYCurve(t *testing.T) {
+	chart := testChart()
+	chart.Unit = unitInchS
+	chart.DPI = 800
+	chart.PointsY = []curvePoint{{X: 0, Y: 0.5}, {X: 80, Y: 3}}
+	svg := chart.SVG()
+	// 80 counts/ms at 800 DPI is 100 in/s
+	for _, want := range []string{"input speed (in/s)", ">100.0<", "sensitivity Y", svgColor(imageSensY)} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("no %q in the svg", want)
+		}
+	}
+	if strings.Contains(svg, "counts/ms") {
+		t.Error("svg still labelled in counts/ms")
+	}
+	// The Y curve widens the axis
+	if !strings.Contains(svg, ">3.00<") || !strings.Contains(svg, ">0.50<") {
+		t.Error("sensitivity axis does not cover the Y curve")
+	}
+}
+
+func TestChartPNG(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeChartImage(&buf, testChart(), imageFormatPNG); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("invalid png: %v", err)
+	}
+	if size := img.Bounds().Size(); size.X != 400 || size.Y != 250 {
+		t.Errorf("size %v, want 400x250", size)
+	}
+	counts := map[[3]uint32]int{}
+	for y := img.Bounds().Min.Y; y < img.Bounds().Max.Y; y++ {
+		for x := img.Bounds().Min.X; x < img.Bounds().Max.X; x++ {
+			r, g, b, _ := img.At(x, y).RGBA()
+			counts[[3]uint32{r >> 8, g >> 8, b >> 8}]++
+		}
+	}
+	for name, c := range map[string]struct{ R, G, B uint8 }{
+		"sensitivity": {imageSens.R, imageSens.G, imageSens.B},
+		"velocity":    {imageVelocity.R, imageVelocity.G, imageVelocity.B},
+		"gain":        {imageGain.R, imageGain.G, imageGain.B},
+		"axis":        {imageAxis.R, imageAxis.G, imageAxis.B},
+	} {
+		if counts[[3]uint32{uint32(c.R), uint32(c.G), uint32(c.B)}] == 0 {
+			t.Errorf("no %s pixel", name)
+		}
+	}
+	if white := counts[[3]uint32{0xff, 0xff, 0xff}]; white == 400*250 {
+		t.Error("blank image")
+	}
+
+	if err := writeChartImage(&buf, testChart(), "gif"); err == nil {
+		t.Error("no error for an unknown format")
+	}
+}
@@ -0,0 +1,382 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Headless commands, the window is only opened when no command is given
+var cliCommands = map[string]func(args []string) error{
+	"export":   cliExport,
+	"import":   cliImport,
+	"image":    cliImage,
+	"libinput": cliLibinput,
+	"linux":    cliLinux,
+	"settings": cliSettings,
+	"share":    cliShare,
+	"trace":    cliTrace,
+}
+
+func runCLI(args []string) int {
+	command, ok := cliCommands[args[0]]
+	if !ok {
+		cliUsage()
+		return 2
+	}
+	if err := command(args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "rawAccelGraph:", err)
+		return 1
+	}
+	return 0
+}
+
+func cliUsage() {
+	fmt.Fprintln(os.Stderr, "usage: rawAccelGraph [command] [flags]")
+	fmt.Fprintln(os.Stderr, "without command the graphical interface is opened")
+	fmt.Fprintln(os.Stderr, "commands:")
+	fmt.Fprintln(os.Stderr, "  export   write the curve of a profile as csv or json")
+	fmt.Fprintln(os.Stderr, "  import   create a profile from a csv or json curve")
+	fmt.Fprintln(os.Stderr, "  image    draw the curve of a profile as svg or png")
+	fmt.Fprintln(os.Stderr, "  libinput write a libinput custom acceleration profile")
+	fmt.Fprintln(os.Stderr, "  linux    write leetmouse or maccel parameters")
+	fmt.Fprintln(os.Stderr, "  settings write a Raw Accel settings.json with the curve")
+	fmt.Fprintln(os.Stderr, "  share    print the share code of a profile or save a share code")
+	fmt.Fprintln(os.Stderr, "  trace    record an evdev device or convert an evdev log to a motion trace")
+}
+
+type csvFlags struct {
+	delimiter    *string
+	decimalComma *bool
+}
+
+func addCSVFlags(fs *flag.FlagSet) csvFlags {
+	return csvFlags{
+		delimiter:    fs.String("delimiter", ",", "csv delimiter, \"tab\" for tabulations"),
+		decimalComma: fs.Bool("decimal-comma", false, "use a comma as decimal separator"),
+	}
+}
+
+func (f csvFlags) options() csvOptions {
+	return csvOptions{Delimiter: delimiterRune(*f.delimiter), DecimalComma: *f.decimalComma}
+}
+
+func cliExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	conf := fs.String("config", "current.yml", "profile in the configs folder")
+	format := fs.String("format", formatCSV, "csv or json")
+	output := fs.String("o", "", "output file, standard output when empty")
+	csvOpts := addCSVFlags(fs)
+	force := fs.Bool("force", false, "export even when the curve breaks the profile constraints")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := readExportConfig(*conf, *force)
+	if err != nil {
+		return err
+	}
+	return writeOutput(*output, func(w io.Writer) error {
+		return writePoints(w, configCurve(cfg), *format, csvOpts.options())
+	})
+}
+
+func cliImport(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	conf := fs.String("config", "", "profile to create in the configs folder")
+	base := fs.String("base", "", "profile to take the settings from")
+	format := fs.String("format", formatCSV, "csv or json")
+	input := fs.String("i", "", "input file, standard input when empty")
+	csvOpts := addCSVFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *conf == "" {
+		return fmt.Errorf("-config is required")
+	}
+
+	var r io.Reader = os.Stdin
+	if *input != "" {
+		file, err := os.Open(*input)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		r = file
+	}
+	points, err := readPoints(r, *format, csvOpts.options())
+	if err != nil {
+		return err
+	}
+
+	var baseConf Config
+	if *base != "" {
+		if baseConf, err = readConfig(*base); err != nil {
+			return err
+		}
+	}
+	return writeConfig(*conf, pointsConfig(baseConf, points))
+}
+
+func cliImage(args []string) error {
+	fs := flag.NewFlagSet("image", flag.ContinueOnError)
+	conf := fs.String("config", "current.yml", "profile in the configs folder")
+	format := fs.String("format", imageFormatSVG, "svg or png")
+	output := fs.String("o", "", "output file, standard output when empty")
+	title := fs.String("title", "", "title, the profile name when empty")
+	width := fs.Int("width", 800, "image width")
+	height := fs.Int("height", 500, "image height")
+	velocity := fs.Bool("velocity", false, "overlay the output velocity")
+	gain := fs.Bool("gain", false, "overlay the gain")
+	grid := fs.Bool("grid", true, "draw the grid")
+	labels := fs.Bool("labels", true, "draw axis labels and legend")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := readConfig(*conf)
+	if err != nil {
+		return err
+	}
+	if *title == "" {
+		*title = *conf
+	}
+	chart := chartImage{
+		Points:   configCurve(cfg),
+		Title:    *title,
+		Unit:     cfg.ConfUnit,
+		DPI:      configDPI(cfg),
+		Velocity: *velocity,
+		Gain:     *gain,
+		Grid:     *grid,
+		Labels:   *labels,
+		Width:    *width,
+		Height:   *height,
+	}
+	if cfg.ConfRawAccel.SeparateXY {
+		chart.PointsY = configCurveY(cfg)
+	}
+	return writeOutput(*output, func(w io.Writer) error {
+		return writeChartImage(w, chart, *format)
+	})
+}
+
+func cliLibinput(args []string) error {
+	fs := flag.NewFlagSet("libinput", flag.ContinueOnError)
+	conf := fs.String("config", "current.yml", "profile in the configs folder")
+	format := fs.String("format", libinputXinput, "xinput, hyprland or sway")
+	motion := fs.String("motion", libinputMotion, "motion, fallback or scroll")
+	dpi := fs.Float64("dpi", 0, "mouse DPI, the profile DPI when 0")
+	step := fs.Float64("step", 0, "input speed step in units/ms, 0 spreads the points over the curve")
+	count := fs.Int("points", libinputMaxPoints, "number of points")
+	device := fs.String("device", "", "device name for xinput and sway")
+	output := fs.String("o", "", "output file, standard output when empty")
+	force := fs.Bool("force", false, "export even when the curve breaks the profile constraints")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := readExportConfig(*conf, *force)
+	if err != nil {
+		return err
+	}
+	if *dpi == 0 {
+		*dpi = configDPI(cfg)
+	}
+	profile, err := resampleLibinput(configCurve(cfg), *dpi, *step, *count, *motion)
+	if err != nil {
+		return err
+	}
+	text, err := profile.export(*format, *device)
+	if err != nil {
+		return err
+	}
+	return writeOutput(*output, func(w io.Writer) error {
+		_, err := io.WriteString(w, text)
+		return err
+	})
+}
+
+func cliLinux(args []string) error {
+	fs := flag.NewFlagSet("linux", flag.ContinueOnError)
+	conf := fs.String("config", "current.yml", "profile in the configs folder")
+	driver := fs.String("driver", driverMaccel, "leetmouse or maccel")
+	dpi := fs.Float64("dpi", 0, "mouse DPI, the profile DPI when 0")
+	root := fs.String("root", maccelRoot, "maccel parameters directory")
+	apply := fs.Bool("apply", false, "write the maccel parameters to -root instead of printing a script")
+	output := fs.String("o", "", "output file, standard output when empty")
+	force := fs.Bool("force", false, "export even when the curve breaks the profile constraints")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := readExportConfig(*conf, *force)
+	if err != nil {
+		return err
+	}
+	if *dpi == 0 {
+		*dpi = configDPI(cfg)
+	}
+	params, err := fitDriver(*driver, configCurve(cfg), *dpi)
+	if err != nil {
+		return err
+	}
+	if *apply {
+		return writeMaccelParams(*root, params)
+	}
+	return writeOutput(*output, func(w io.Writer) error {
+		_, err := io.WriteString(w, params.export(*root))
+		return err
+	})
+}
+
+func cliSettings(args []string) error {
+	fs := flag.NewFlagSet("settings", flag.ContinueOnError)
+	conf := fs.String("config", "current.yml", "profile in the configs folder")
+	output := fs.String("o", "", "output file, standard output when empty")
+	force := fs.Bool("force", false, "export even when the curve breaks the profile constraints")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := readExportConfig(*conf, *force)
+	if err != nil {
+		return err
+	}
+	settings := cfg.ConfRawAccel
+	if settings == (RawAccelSettings{}) {
+		settings = defaultRawAccelSettings()
+	}
+	return writeOutput(*output, func(w io.Writer) error {
+		return writeRawAccelSettings(w, strings.TrimSuffix(*conf, ".yml"), settings, configTable(cfg), configTableY(cfg))
+	})
+}
+
+func cliShare(args []string) error {
+	fs := flag.NewFlagSet("share", flag.ContinueOnError)
+	conf := fs.String("config", "", "profile in the configs folder, current.yml when printing a code")
+	code := fs.String("code", "", "share code to save as -config instead of printing one")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *code != "" {
+		// Saving a code never falls back to the current profile
+		if *conf == "" {
+			return fmt.Errorf("-config is required with -code")
+		}
+		profile, err := decodeShareCode(*code)
+		if err != nil {
+			return err
+		}
+		return writeConfig(*conf, profile.Config)
+	}
+	if *conf == "" {
+		*conf = "current.yml"
+	}
+	cfg, err := readConfig(*conf)
+	if err != nil {
+		return err
+	}
+	text, err := encodeShareCode(strings.TrimSuffix(*conf, ".yml"), cfg)
+	if err != nil {
+		return err
+	}
+	fmt.Println(text)
+	return nil
+}
+
+func cliTrace(args []string) error {
+	fs := flag.NewFlagSet("trace", flag.ContinueOnError)
+	device := fs.String("device", "", "evdev device to record, like /dev/input/event5")
+	duration := fs.Duration("duration", 10*time.Second, "recording time")
+	input := fs.String("i", "", "evdev dump, evtest or libinput record log to convert")
+	output := fs.String("o", "", "output file, standard output when empty")
+	list := fs.Bool("list", false, "list the evdev devices")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *list {
+		for _, d := range listEvdevDevices() {
+			fmt.Println(d)
+		}
+		return nil
+	}
+	var events []motionEvent
+	switch {
+	case *device != "":
+		stop := make(chan struct{})
+		time.AfterFunc(*duration, func() { close(stop) })
+		var err error
+		if events, err = recordEvdev(*device, stop); err != nil {
+			return err
+		}
+	case *input != "":
+		file, err := os.Open(*input)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		raw, err := readEvdevFile(file)
+		if err != nil {
+			return err
+		}
+		events = evdevMotion(raw)
+	default:
+		return fmt.Errorf("-device or -i is required")
+	}
+	if rate := estimatePollingRate(events); rate > 0 {
+		fmt.Fprintf(os.Stderr, "%d events, polling rate about %s Hz\n", len(events), formatFloat(rate, 0))
+	}
+	return writeOutput(*output, func(w io.Writer) error {
+		return writeTrace(w, events)
+	})
+}
+
+// Profile for an export, refused when its curves break its constraints unless forced
+func readExportConfig(conf string, force bool) (Config, error) {
+	cfg, err := readConfig(conf)
+	if err != nil {
+		return cfg, err
+	}
+	step := sliderStep(cfg.ConfOrdonneesMax)
+	list := cfg.ConfConstraints.violations(configCurve(cfg), step)
+	if cfg.ConfRawAccel.SeparateXY {
+		list = append(list, cfg.ConfConstraints.violations(configCurveY(cfg), step)...)
+	}
+	if len(list) > 0 && !force {
+		return cfg, fmt.Errorf("%s does not follow its constraints (%s), use -force to export anyway", conf, list[0])
+	}
+	return cfg, nil
+}
+
+func writeConfig(conf string, cfg Config) error {
+	_ = os.Mkdir("configs/", 0755)
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile("configs/"+conf, data, 0644)
+}
+
+func writeOutput(output string, write func(w io.Writer) error) error {
+	if output == "" {
+		return write(os.Stdout)
+	}
+	file, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	if err := write(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,314 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	constraintAdjust = "Adjust neighbors"
+	constraintBlock  = "Block slider"
+
+	constraintTolerance = 1e-3
+)
+
+// Optional rules attached to a profile, zero values are disabled
+type Constraints struct {
+	NonDecreasingSens     bool
+	NonDecreasingVelocity bool
+	MaxGain               float64
+	OutputCap             float64
+	MinSens               float64
+	Mode                  string
+}
+
+var profileConstraints Constraints
+
+// Set while neighbors are moved so their OnChanged do not enforce again
+var enforcing bool
+
+func (c Constraints) active() bool {
+	return c.NonDecreasingSens || c.NonDecreasingVelocity || c.MaxGain > 0 || c.OutputCap > 0 || c.MinSens > 0
+}
+
+// Limits that do not depend on the neighbors
+func (c Constraints) absoluteBounds(p curvePoint) (float64, float64) {
+	lo, hi := math.Inf(-1), math.Inf(1)
+	if c.MinSens > 0 {
+		lo = c.MinSens
+	}
+	if c.OutputCap > 0 && p.X > 0 {
+		hi = c.OutputCap / p.X
+	}
+	return lo, hi
+}
+
+// Limits on point i coming from the previous point
+func (c Constraints) boundsFromPrev(prev, p curvePoint) (float64, float64) {
+	lo, hi := math.Inf(-1), math.Inf(1)
+	if c.NonDecreasingSens {
+		lo = math.Max(lo, prev.Y)
+	}
+	if c.NonDecreasingVelocity && p.X > 0 {
+		lo = math.Max(lo, prev.X*prev.Y/p.X)
+	}
+	if c.MaxGain > 0 && p.X > 0 {
+		hi = math.Min(hi, (prev.X*prev.Y+c.MaxGain*(p.X-prev.X))/p.X)
+	}
+	return lo, hi
+}
+
+// Limits on point i coming from the next point
+func (c Constraints) boundsFromNext(p, next curvePoint) (float64, float64) {
+	lo, hi := math.Inf(-1), math.Inf(1)
+	if c.NonDecreasingSens {
+		hi = math.Min(hi, next.Y)
+	}
+	if c.NonDecreasingVelocity && p.X > 0 {
+		hi = math.Min(hi, next.X*next.Y/p.X)
+	}
+	if c.MaxGain > 0 && p.X > 0 {
+		lo = math.Max(lo, (next.X*next.Y-c.MaxGain*(next.X-p.X))/p.X)
+	}
+	return lo, hi
+}
+
+func (c Constraints) bounds(points []curvePoint, i int) (float64, float64) {
+	lo, hi := c.absoluteBounds(points[i])
+	if i > 0 {
+		l, h := c.boundsFromPrev(points[i-1], points[i])
+		lo, hi = math.Max(lo, l), math.Min(hi, h)
+	}
+	if i < len(points)-1 {
+		l, h := c.boundsFromNext(points[i], points[i+1])
+		lo, hi = math.Max(lo, l), math.Min(hi, h)
+	}
+	return lo, hi
+}
+
+func clamp(value, lo, hi float64) float64 {
+	return math.Max(math.Min(value, hi), lo)
+}
+
+// Bounds moved inward onto the multiples of step, where a slider can stop.
+// A slider set to them stays inside.
+func snapBounds(lo, hi, step float64) (float64, float64) {
+	if step <= 0 {
+		return lo, hi
+	}
+	return math.Ceil(lo/step-1e-9) * step, math.Floor(hi/step+1e-9) * step
+}
+
+// Keep point i where the user put it and move the others outward until the
+// whole curve follows the constraints. The moved values stay on the slider
+// steps so setting them does not break the constraints again.
+func (c Constraints) adjust(points []curvePoint, i int, step float64) []float64 {
+	adjusted := append([]curvePoint(nil), points...)
+	lo, hi := c.absoluteBounds(adjusted[i])
+	adjusted[i].Y = snappedClamp(adjusted[i].Y, lo, hi, step)
+	for j := i + 1; j < len(adjusted); j++ {
+		lo, hi := c.absoluteBounds(adjusted[j])
+		l, h := c.boundsFromPrev(adjusted[j-1], adjusted[j])
+		adjusted[j].Y = snappedClamp(snappedClamp(adjusted[j].Y, l, h, step), lo, hi, step)
+	}
+	for j := i - 1; j >= 0; j-- {
+		lo, hi := c.absoluteBounds(adjusted[j])
+		l, h := c.boundsFromNext(adjusted[j], adjusted[j+1])
+		adjusted[j].Y = snappedClamp(snappedClamp(adjusted[j].Y, l, h, step), lo, hi, step)
+	}
+	return curveValues(adjusted)
+}
+
+func snappedClamp(value, lo, hi, step float64) float64 {
+	lo, hi = snapBounds(lo, hi, step)
+	return clamp(value, lo, hi)
+}
+
+// Largest rounding of a sensitivity set with a slider of this step, the
+// output speed is off by up to x times it
+func constraintSlack(step float64) float64 {
+	return math.Max(constraintTolerance, step)
+}
+
+// Broken constraints, step is the slider step of the curve so values only
+// rounded onto it do not count
+func (c Constraints) violations(points []curvePoint, step float64) []string {
+	var list []string
+	slack := constraintSlack(step)
+	for i, p := range points {
+		x := formatFloat(p.X, 0)
+		if c.MinSens > 0 && p.Y < c.MinSens-slack {
+			list = append(list, fmt.Sprintf("x=%s: sensitivity %s below minimum %s", x, formatFloat(p.Y, 3), formatFloat(c.MinSens, 3)))
+		}
+		if c.OutputCap > 0 && p.X*p.Y > c.OutputCap+slack*math.Max(p.X, 1) {
+			list = append(list, fmt.Sprintf("x=%s: output %s above cap %s", x, formatFloat(p.X*p.Y, 3), formatFloat(c.OutputCap, 3)))
+		}
+		if i == 0 {
+			continue
+		}
+		prev := points[i-1]
+		if c.NonDecreasingSens && p.Y < prev.Y-slack {
+			list = append(list, fmt.Sprintf("x=%s: sensitivity decreases", x))
+		}
+		if c.NonDecreasingVelocity && p.X*p.Y < prev.X*prev.Y-slack*math.Max(p.X, 1) {
+			list = append(list, fmt.Sprintf("x=%s: output velocity decreases", x))
+		}
+		if c.MaxGain > 0 && p.X > prev.X {
+			gain := (p.X*p.Y - prev.X*prev.Y) / (p.X - prev.X)
+			if gain > c.MaxGain+slack*math.Max(p.X+prev.X, 1)/(p.X-prev.X) {
+				list = append(list, fmt.Sprintf("x=%s: gain %s above maximum %s", x, formatFloat(gain, 3), formatFloat(c.MaxGain, 3)))
+			}
+		}
+	}
+	return list
+}
+
+// Called by a slider before its value is used, returns false when the
+// slider was moved again by the constraints
+func enforceConstraints(key float64, value float64) bool {
+	if enforcing || !profileConstraints.active() {
+		return true
+	}
+	points := currentCurve()
+	index := -1
+	for i, p := range points {
+		if p.X == key {
+			index = i
+		}
+	}
+	if index < 0 {
+		return true
+	}
+	step := ui.Sliders[key].Step
+	// The slider rounds what it is set to, a value that close is where it was sent
+	moveTo := func(target float64) bool {
+		if math.Abs(target-value) <= step/1000 {
+			return true
+		}
+		ui.Sliders[key].SetValue(target)
+		return false
+	}
+
+	if profileConstraints.Mode == constraintBlock {
+		lo, hi := profileConstraints.bounds(points, index)
+		lo, hi = snapBounds(lo, hi, step)
+		if lo > hi {
+			return true
+		}
+		return moveTo(clamp(value, lo, hi))
+	}
+
+	values := profileConstraints.adjust(points, index, step)
+	if len(profileConstraints.violations(withValues(points, values), step)) > 0 {
+		// No way to keep the point there, behave like a blocked slider
+		lo, hi := profileConstraints.bounds(points, index)
+		lo, hi = snapBounds(lo, hi, step)
+		if lo > hi {
+			return true
+		}
+		return moveTo(clamp(value, lo, hi))
+	}
+	enforcing = true
+	for i, p := range points {
+		if i != index {
+			ui.Sliders[p.X].SetValue(values[i])
+		}
+	}
+	enforcing = false
+	return moveTo(values[index])
+}
+
+// Violations of the curves written by the exports, Y included when it is separate
+func exportViolations() []string {
+	step := sliderStep(set.OrdonneesMax.Text)
+	list := profileConstraints.violations(currentCurve(), step)
+	if profileSettings.SeparateXY {
+		for _, v := range profileConstraints.violations(currentCurveY(), step) {
+			list = append(list, "Y "+v)
+		}
+	}
+	return list
+}
+
+// Action asking first when the exported curve breaks its constraints
+func checkedExport(action string, export func()) func() {
+	return func() {
+		if len(exportViolations()) == 0 {
+			export()
+			return
+		}
+		dialog.ShowConfirm("Constraint violations", "The curve does not follow its constraints.\n"+action+" anyway?", func(ok bool) {
+			if ok {
+				export()
+			}
+		}, fyneApp.Window)
+	}
+}
+
+func refreshViolations() {
+	if set.Violations == nil {
+		return
+	}
+	list := exportViolations()
+	if len(list) == 0 {
+		set.Violations.SetText("No constraint violation")
+		return
+	}
+	set.Violations.SetText(strings.Join(list, "\n"))
+}
+
+func showConstraintsDialog() {
+	c := profileConstraints
+	sens := widget.NewCheck("", nil)
+	sens.Checked = c.NonDecreasingSens
+	velocity := widget.NewCheck("", nil)
+	velocity.Checked = c.NonDecreasingVelocity
+	maxGain := widget.NewEntry()
+	maxGain.Text = formatFloat(c.MaxGain, 3)
+	outputCap := widget.NewEntry()
+	outputCap.Text = formatFloat(c.OutputCap, 3)
+	minSens := widget.NewEntry()
+	minSens.Text = formatFloat(c.MinSens, 3)
+	mode := widget.NewRadioGroup([]string{constraintAdjust, constraintBlock}, nil)
+	mode.Selected = c.Mode
+	if mode.Selected == "" {
+		mode.Selected = constraintAdjust
+	}
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Non-decreasing sensitivity", sens),
+		widget.NewFormItem("Non-decreasing output velocity", velocity),
+		widget.NewFormItem("Maximum gain (0 = off)", maxGain),
+		widget.NewFormItem("Output cap (0 = off)", outputCap),
+		widget.NewFormItem("Minimum sensitivity (0 = off)", minSens),
+		widget.NewFormItem("While dragging", mode),
+	}
+	dialog.ShowForm("Curve constraints", "Save", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		var err error
+		c.NonDecreasingSens = sens.Checked
+		c.NonDecreasingVelocity = velocity.Checked
+		c.Mode = mode.Selected
+		if c.MaxGain, err = strconv.ParseFloat(maxGain.Text, 64); err != nil {
+			errorDialog(err)
+			return
+		}
+		if c.OutputCap, err = strconv.ParseFloat(outputCap.Text, 64); err != nil {
+			errorDialog(err)
+			return
+		}
+		if c.MinSens, err = strconv.ParseFloat(minSens.Text, 64); err != nil {
+			errorDialog(err)
+			return
+		}
+		profileConstraints = c
+		refreshViolations()
+	}, fyneApp.Window)
+}
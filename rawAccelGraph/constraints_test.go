@@ -0,0 +1,118 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestConstraintBounds(t *testing.T) {
+	points := []curvePoint{{X: 1, Y: 1}, {X: 10, Y: 1.5}, {X: 20, Y: 2}}
+	inf := math.Inf(1)
+	tests := []struct {
+		name   string
+		c      Constraints
+		i      int
+		lo, hi float64
+	}{
+		{"none", Constraints{}, 1, -inf, inf},
+		{"sensitivity", Constraints{NonDecreasingSens: true}, 1, 1, 2},
+		// Output 1 before and 40 after at 10 counts/ms
+		{"velocity", Constraints{NonDecreasingVelocity: true}, 1, 0.1, 4},
+		// Output 1 + 9*0.2 at most, 40 - 10*0.2 at least
+		{"gain", Constraints{MaxGain: 0.2}, 1, 3.8, 0.28},
+		{"cap and minimum", Constraints{OutputCap: 12, MinSens: 0.8}, 1, 0.8, 1.2},
+		{"first point", Constraints{NonDecreasingSens: true}, 0, -inf, 1.5},
+		{"last point", Constraints{NonDecreasingSens: true}, 2, 1.5, inf},
+	}
+	for _, test := range tests {
+		lo, hi := test.c.bounds(points, test.i)
+		if !(lo == test.lo || almostEqual(lo, test.lo)) || !(hi == test.hi || almostEqual(hi, test.hi)) {
+			t.Errorf("%s: bounds [%v, %v], want [%v, %v]", test.name, lo, hi, test.lo, test.hi)
+		}
+	}
+}
+
+func TestConstraintAdjust(t *testing.T) {
+	points := []curvePoint{{X: 1, Y: 1}, {X: 10, Y: 1.2}, {X: 20, Y: 1.4}, {X: 30, Y: 1.6}}
+	tests := []struct {
+		name  string
+		c     Constraints
+		i     int
+		value float64
+		step  float64
+		want  []float64
+	}{
+		// Raised above its neighbor, the next points follow
+		{"push up", Constraints{NonDecreasingSens: true}, 1, 1.5, 0, []float64{1, 1.5, 1.5, 1.6}},
+		// Lowered, the previous points follow
+		{"push down", Constraints{NonDecreasingSens: true}, 2, 0.9, 0, []float64{0.9, 0.9, 0.9, 1.6}},
+		// The moved point itself only follows the absolute limits
+		{"minimum", Constraints{MinSens: 1.1}, 1, 0.5, 0, []float64{1.1, 1.1, 1.4, 1.6}},
+		// Output velocity of the next point kept at 10 * 3, snapped up to the 0.25 steps
+		{"velocity on steps", Constraints{NonDecreasingVelocity: true}, 1, 3, 0.25, []float64{1, 3, 1.5, 1.6}},
+	}
+	for _, test := range tests {
+		moved := withValues(points, curveValues(points))
+		moved[test.i].Y = test.value
+		got := test.c.adjust(moved, test.i, test.step)
+		for j := range test.want {
+			if !almostEqual(got[j], test.want[j]) {
+				t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+				break
+			}
+		}
+		if v := test.c.violations(withValues(points, got), test.step); len(v) > 0 {
+			t.Errorf("%s: adjusted curve breaks the constraints: %v", test.name, v)
+		}
+	}
+}
+
+func TestConstraintViolations(t *testing.T) {
+	tests := []struct {
+		name   string
+		c      Constraints
+		points []curvePoint
+		step   float64
+		want   []string
+	}{
+		{"valid", Constraints{NonDecreasingSens: true, NonDecreasingVelocity: true, MaxGain: 3, OutputCap: 100, MinSens: 1},
+			[]curvePoint{{X: 1, Y: 1}, {X: 10, Y: 1.5}, {X: 20, Y: 2}}, 0, nil},
+		{"sensitivity", Constraints{NonDecreasingSens: true},
+			[]curvePoint{{X: 1, Y: 1}, {X: 10, Y: 0.9}}, 0, []string{"x=10: sensitivity decreases"}},
+		{"velocity", Constraints{NonDecreasingVelocity: true},
+			[]curvePoint{{X: 10, Y: 2}, {X: 20, Y: 0.9}}, 0, []string{"x=20: output velocity decreases"}},
+		{"gain", Constraints{MaxGain: 1},
+			[]curvePoint{{X: 10, Y: 1}, {X: 20, Y: 2}}, 0, []string{"x=20: gain 3.000 above maximum 1.000"}},
+		{"cap", Constraints{OutputCap: 30},
+			[]curvePoint{{X: 10, Y: 1}, {X: 20, Y: 2}}, 0, []string{"x=20: output 40.000 above cap 30.000"}},
+		{"minimum", Constraints{MinSens: 1},
+			[]curvePoint{{X: 10, Y: 0.5}, {X: 20, Y: 2}}, 0, []string{"x=10: sensitivity 0.500 below minimum 1.000"}},
+		// Off by less than a step at 40 counts/ms, only rounding
+		{"velocity within a step", Constraints{NonDecreasingVelocity: true},
+			[]curvePoint{{X: 20, Y: 2}, {X: 40, Y: 0.999}}, 0.002, nil},
+		{"velocity beyond a step", Constraints{NonDecreasingVelocity: true},
+			[]curvePoint{{X: 20, Y: 2}, {X: 40, Y: 0.99}}, 0.002, []string{"x=40: output velocity decreases"}},
+	}
+	for _, test := range tests {
+		got := test.c.violations(test.points, test.step)
+		if strings.Join(got, "\n") != strings.Join(test.want, "\n") {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestSnapBounds(t *testing.T) {
+	lo, hi := snapBounds(1.01, 1.49, 0.25)
+	if lo != 1.25 || hi != 1.25 {
+		t.Errorf("got [%v, %v], want [1.25, 1.25]", lo, hi)
+	}
+	// Bounds already on a step don't move
+	lo, hi = snapBounds(0.5, 3/2160.0*1000, 3/2160.0)
+	if !almostEqual(lo, 0.5) || !almostEqual(hi, 3/2160.0*1000) {
+		t.Errorf("got [%v, %v]", lo, hi)
+	}
+	if lo, hi := snapBounds(math.Inf(-1), 2, 0); !math.IsInf(lo, -1) || hi != 2 {
+		t.Errorf("without step: got [%v, %v]", lo, hi)
+	}
+}
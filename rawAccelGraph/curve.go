@@ -0,0 +1,74 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+)
+
+type curvePoint struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// Slider keys in increasing abscissa order
+func sliderKeys() []float64 {
+	keys := make([]float64, 0, len(ui.Sliders))
+	for key := range ui.Sliders {
+		keys = append(keys, key)
+	}
+	sort.Float64s(keys)
+	return keys
+}
+
+func currentCurve() []curvePoint {
+	var points []curvePoint
+	for _, key := range sliderKeys() {
+		points = append(points, curvePoint{X: key, Y: ui.Sliders[key].Value})
+	}
+	return points
+}
+
+func curveValues(points []curvePoint) []float64 {
+	values := make([]float64, len(points))
+	for i, p := range points {
+		values[i] = p.Y
+	}
+	return values
+}
+
+func withValues(points []curvePoint, values []float64) []curvePoint {
+	result := make([]curvePoint, len(points))
+	for i, p := range points {
+		result[i] = curvePoint{X: p.X, Y: values[i]}
+	}
+	return result
+}
+
+// Push new values into the sliders, OnChanged keeps labels and rawAccel.Data in sync
+func applyCurve(values []float64) {
+	for i, key := range sliderKeys() {
+		if i >= len(values) {
+			break
+		}
+		ui.Sliders[key].SetValue(values[i])
+	}
+	genAccelRaw()
+}
+
+// Indexes of the points whose abscissa is in [from, to]
+func curveRange(points []curvePoint, from, to float64) (int, int) {
+	start, end := -1, -1
+	for i, p := range points {
+		if p.X >= from && p.X <= to {
+			if start < 0 {
+				start = i
+			}
+			end = i
+		}
+	}
+	return start, end
+}
+
+func formatFloat(f float64, prec int) string {
+	return strconv.FormatFloat(f, 'f', prec, 64)
+}
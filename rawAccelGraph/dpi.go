@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Rescale a profile for a new DPI so the same hand speed gives the same
+// cursor speed. With normalize the sensitivity values are kept as they are
+// and the Raw Accel sensitivity multiplier is divided by the DPI ratio.
+func convertDPI(cfg Config, oldDPI, newDPI float64, normalize bool) (Config, error) {
+	if oldDPI <= 0 || newDPI <= 0 {
+		return cfg, fmt.Errorf("DPI must be positive")
+	}
+	ratio := newDPI / oldDPI
+	old := configCurve(cfg)
+
+	sizeAbisses, err := strconv.ParseFloat(cfg.ConfAbcisses, 64)
+	if err != nil {
+		return cfg, err
+	}
+	converted := cfg
+	converted.ConfAbcisses = strconv.Itoa(int(math.Round(sizeAbisses * ratio)))
+	converted.ConfDPI = strconv.FormatFloat(newDPI, 'f', -1, 64)
+	if cfg.ConfGrid != nil {
+		converted.ConfGrid = scaleGrid(cfg.ConfGrid, ratio)
+	}
+	if normalize {
+		settings := cfg.ConfRawAccel
+		if settings == (RawAccelSettings{}) {
+			settings = defaultRawAccelSettings()
+		}
+		settings.SensMultiplier /= ratio
+		converted.ConfRawAccel = settings
+	}
+
+	// Same counting as configCurve so the keys match the future sliders
+	grid := configCurve(converted)
+	for i, p := range grid {
+		y := interpolate(old, p.X/ratio)
+		if !normalize {
+			y = y / ratio
+		}
+		grid[i].Y = y
+	}
+	return curveConfig(converted, grid), nil
+}
+
+// Curve at oldDPI moved onto the counts of the new DPI, the way convertDPI
+// moves the sliders
+func rescaleDPI(points []curvePoint, ratio float64, normalize bool) []curvePoint {
+	result := make([]curvePoint, len(points))
+	for i, p := range points {
+		result[i] = curvePoint{X: p.X * ratio, Y: p.Y}
+		if !normalize {
+			result[i].Y /= ratio
+		}
+	}
+	return result
+}
+
+// Output speed in counts/ms against hand speed in cm/s, divided by the
+// multiplier correction when the sensitivity is normalized
+func physicalCurve(points []curvePoint, dpi, correction float64) []curvePoint {
+	result := make([]curvePoint, len(points))
+	for i, p := range points {
+		result[i] = curvePoint{X: p.X * unitFactor(unitCmS, dpi), Y: p.X * p.Y / correction}
+	}
+	return result
+}
+
+func showConvertDPIDialog() {
+	oldDPI := widget.NewEntry()
+	oldDPI.Text = formatFloat(settingsDPI(), 0)
+	newDPI := widget.NewEntry()
+	newDPI.Text = formatFloat(settingsDPI()*2, 0)
+	normalize := widget.NewCheck("Keep sensitivity values, divide the sensitivity multiplier instead", nil)
+	status := widget.NewLabel("")
+	chart := newCurveChart()
+
+	var converted Config
+	var ratio float64
+	update := func() {
+		from, err1 := strconv.ParseFloat(oldDPI.Text, 64)
+		to, err2 := strconv.ParseFloat(newDPI.Text, 64)
+		if err1 != nil || err2 != nil {
+			status.SetText("Invalid DPI")
+			return
+		}
+		var err error
+		ratio = to / from
+		converted, err = convertDPI(currentConfig(), from, to, normalize.Checked)
+		if err != nil {
+			status.SetText(err.Error())
+			return
+		}
+		correction := 1.0
+		if normalize.Checked {
+			correction = to / from
+		}
+		status.SetText("New Input Speed: " + converted.ConfAbcisses + " counts/ms")
+		chart.SetSeries(
+			chartSeries{Points: physicalCurve(currentCurve(), from, 1), Color: theme.DisabledColor(), Width: 3},
+			chartSeries{Points: physicalCurve(configCurve(converted), to, correction), Color: theme.PrimaryColor(), Width: 1},
+		)
+	}
+	oldDPI.OnChanged = func(string) { update() }
+	newDPI.OnChanged = func(string) { update() }
+	normalize.OnChanged = func(bool) { update() }
+	update()
+
+	form := widget.NewForm(
+		widget.NewFormItem("Old DPI", oldDPI),
+		widget.NewFormItem("New DPI", newDPI),
+		widget.NewFormItem("", normalize),
+	)
+	content := container.NewVBox(form, status, widget.NewLabel("Output counts/ms against hand speed in cm/s"), chart)
+	dpiDial := dialog.NewCustomConfirm("Convert DPI", "Convert", "Cancel", content, func(ok bool) {
+		if !ok || converted.ConfGraph == nil {
+			return
+		}
+		// The old curve drawn on the new counts for the comparison
+		before := rescaleDPI(currentCurve(), ratio, normalize.Checked)
+		openProfile(converted, currentProfile)
+		setOverlay(chartSeries{Points: before, Color: overlayColor, Width: 1})
+	}, fyneApp.Window)
+	dpiDial.Resize(fyne.NewSize(500, 480))
+	dpiDial.Show()
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func linearConfig(grid []float64) Config {
+	cfg := Config{ConfAbcisses: "250", ConfCollumns: "15", ConfOrdonneesMin: "0", ConfOrdonneesMax: "3", ConfDPI: "800", ConfGrid: grid}
+	points := configCurve(cfg)
+	for i := range points {
+		points[i].Y = 1 + 0.004*points[i].X
+	}
+	return curveConfig(cfg, points)
+}
+
+func TestConvertDPIRoundTrip(t *testing.T) {
+	for _, normalize := range []bool{false, true} {
+		for _, grid := range [][]float64{nil, {2, 10, 20, 40, 80}} {
+			cfg := linearConfig(grid)
+			high, err := convertDPI(cfg, 800, 1600, normalize)
+			if err != nil {
+				t.Fatal(err)
+			}
+			back, err := convertDPI(high, 1600, 800, normalize)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if back.ConfAbcisses != cfg.ConfAbcisses || back.ConfDPI != "800" {
+				t.Errorf("input speed %s at %s DPI, want %s at 800", back.ConfAbcisses, back.ConfDPI, cfg.ConfAbcisses)
+			}
+			want, got := configCurve(cfg), configCurve(back)
+			if len(got) != len(want) {
+				t.Fatalf("%d points, want %d", len(got), len(want))
+			}
+			for i := range want {
+				// The uniform grid starts at 1 on both sides, the first point is interpolated
+				if got[i].X != want[i].X || math.Abs(got[i].Y-want[i].Y) > 0.005 {
+					t.Errorf("normalize %v grid %v: point %d = %v, want %v", normalize, grid, i, got[i], want[i])
+				}
+			}
+		}
+	}
+}
+
+func TestConvertDPIKeepsPhysicalSpeed(t *testing.T) {
+	cfg := linearConfig([]float64{2, 10, 20, 40, 80})
+	high, err := convertDPI(cfg, 800, 1600, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 10 counts/ms at 800 DPI is 20 counts/ms at 1600 DPI, the physical
+	// speed and the output have to be the same
+	before := physicalCurve(configCurve(cfg), 800, 1)[1]
+	after := physicalCurve(configCurve(high), 1600, 1)[1]
+	if !almostEqual(before.X, after.X) || !almostEqual(before.Y, after.Y) {
+		t.Errorf("800 DPI %v, 1600 DPI %v", before, after)
+	}
+
+	if _, err := convertDPI(cfg, 0, 800, false); err == nil {
+		t.Error("no error for a zero DPI")
+	}
+}
+
+func TestConvertDPINormalize(t *testing.T) {
+	cfg := linearConfig(nil)
+	cfg.ConfRawAccel = defaultRawAccelSettings()
+	cfg.ConfRawAccel.SensMultiplier = 1.5
+	high, err := convertDPI(cfg, 800, 1600, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Twice the counts, half the multiplier
+	if !almostEqual(high.ConfRawAccel.SensMultiplier, 0.75) {
+		t.Errorf("multiplier %v, want 0.75", high.ConfRawAccel.SensMultiplier)
+	}
+	plain, _ := convertDPI(cfg, 800, 1600, false)
+	if plain.ConfRawAccel.SensMultiplier != 1.5 {
+		t.Errorf("multiplier changed to %v without normalize", plain.ConfRawAccel.SensMultiplier)
+	}
+	// A profile without Raw Accel settings starts from the default multiplier
+	cfg.ConfRawAccel = RawAccelSettings{}
+	high, _ = convertDPI(cfg, 800, 1600, true)
+	if !almostEqual(high.ConfRawAccel.SensMultiplier, 0.5) {
+		t.Errorf("multiplier %v, want 0.5", high.ConfRawAccel.SensMultiplier)
+	}
+}
+
+func TestRescaleDPIMatchesConversion(t *testing.T) {
+	cfg := linearConfig([]float64{2, 10, 20, 40, 80})
+	for _, normalize := range []bool{false, true} {
+		converted, err := convertDPI(cfg, 800, 1600, normalize)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// On the custom grid every old point lands on a new slider
+		before := rescaleDPI(configCurve(cfg), 2, normalize)
+		after := configCurve(converted)
+		for i := range after {
+			if !almostEqual(before[i].X, after[i].X) || !almostEqual(before[i].Y, after[i].Y) {
+				t.Errorf("normalize %v: overlay %v, converted %v", normalize, before[i], after[i])
+			}
+		}
+	}
+}
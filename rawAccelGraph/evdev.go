@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Linux input_event on 64 bit systems: timeval, type, code, value
+const evdevEventSize = 24
+
+const (
+	evSyn     = 0
+	evRel     = 2
+	relX      = 0
+	relY      = 1
+	synReport = 0
+)
+
+type evdevEvent struct {
+	Time  time.Duration // since the epoch, as stamped by the kernel
+	Type  uint16
+	Code  uint16
+	Value int32
+}
+
+type evdevDevice struct {
+	Path string
+	Name string
+}
+
+var (
+	evtestLine = regexp.MustCompile(`^Event: time (\d+)\.(\d+), (?:type (\d+) \([^)]*\), code (\d+) \([^)]*\), value (-?\d+)|-+ SYN_REPORT -+)`)
+	recordLine = regexp.MustCompile(`^\s*-\s*\[\s*(\d+),\s*(\d+),\s*(\d+),\s*(\d+),\s*(-?\d+)\s*\]`)
+)
+
+// Event devices with their names, the one to read has to be readable by the user
+func listEvdevDevices() []evdevDevice {
+	paths, _ := filepath.Glob("/dev/input/event*")
+	var devices []evdevDevice
+	for _, path := range paths {
+		name, err := ioutil.ReadFile(filepath.Join("/sys/class/input", filepath.Base(path), "device/name"))
+		if err != nil {
+			name = []byte("unknown")
+		}
+		devices = append(devices, evdevDevice{Path: path, Name: strings.TrimSpace(string(name))})
+	}
+	sort.Slice(devices, func(i, j int) bool {
+		return eventNumber(devices[i].Path) < eventNumber(devices[j].Path)
+	})
+	return devices
+}
+
+func eventNumber(path string) int {
+	n, _ := strconv.Atoi(strings.TrimPrefix(filepath.Base(path), "event"))
+	return n
+}
+
+func (d evdevDevice) String() string {
+	return d.Path + " (" + d.Name + ")"
+}
+
+func decodeEvdevEvent(data []byte) evdevEvent {
+	sec := int64(binary.LittleEndian.Uint64(data[0:]))
+	usec := int64(binary.LittleEndian.Uint64(data[8:]))
+	return evdevEvent{
+		Time:  time.Duration(sec)*time.Second + time.Duration(usec)*time.Microsecond,
+		Type:  binary.LittleEndian.Uint16(data[16:]),
+		Code:  binary.LittleEndian.Uint16(data[18:]),
+		Value: int32(binary.LittleEndian.Uint32(data[20:])),
+	}
+}
+
+// Binary input_event stream, as read from a device or saved with cat
+func readEvdevEvents(r io.Reader) ([]evdevEvent, error) {
+	var events []evdevEvent
+	buf := make([]byte, evdevEventSize)
+	for {
+		_, err := io.ReadFull(r, buf)
+		if err == io.EOF {
+			return events, nil
+		}
+		if err != nil {
+			return events, err
+		}
+		events = append(events, decodeEvdevEvent(buf))
+	}
+}
+
+// evtest output or the evdev frames of a libinput record file
+func parseEvdevLog(r io.Reader) ([]evdevEvent, error) {
+	var events []evdevEvent
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if m := evtestLine.FindStringSubmatch(line); m != nil {
+			sec, _ := strconv.ParseInt(m[1], 10, 64)
+			usec, _ := strconv.ParseInt(m[2], 10, 64)
+			e := evdevEvent{Time: time.Duration(sec)*time.Second + time.Duration(usec)*time.Microsecond}
+			if m[3] != "" {
+				typ, _ := strconv.ParseUint(m[3], 10, 16)
+				code, _ := strconv.ParseUint(m[4], 10, 16)
+				value, _ := strconv.ParseInt(m[5], 10, 32)
+				e.Type, e.Code, e.Value = uint16(typ), uint16(code), int32(value)
+			}
+			events = append(events, e)
+		} else if m := recordLine.FindStringSubmatch(line); m != nil {
+			var fields [5]int64
+			for i := range fields {
+				fields[i], _ = strconv.ParseInt(m[i+1], 10, 64)
+			}
+			events = append(events, evdevEvent{
+				Time:  time.Duration(fields[0])*time.Second + time.Duration(fields[1])*time.Microsecond,
+				Type:  uint16(fields[2]),
+				Code:  uint16(fields[3]),
+				Value: int32(fields[4]),
+			})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		return nil, fmt.Errorf("no evtest or libinput record events found")
+	}
+	return events, nil
+}
+
+// Text logs are recognized by their content, anything else is a binary dump
+func readEvdevFile(r io.Reader) ([]evdevEvent, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Contains(data, []byte("Event: time")) || bytes.Contains(data, []byte("evdev:")) {
+		return parseEvdevLog(bytes.NewReader(data))
+	}
+	if len(data)%evdevEventSize != 0 {
+		return nil, fmt.Errorf("not an evdev dump: %d bytes is not a multiple of %d", len(data), evdevEventSize)
+	}
+	return readEvdevEvents(bytes.NewReader(data))
+}
+
+// Sum the relative axes of each SYN_REPORT frame into one motion event
+func evdevMotion(events []evdevEvent) []motionEvent {
+	var motion []motionEvent
+	var start time.Duration
+	var dx, dy float64
+	for _, e := range events {
+		switch {
+		case e.Type == evRel && e.Code == relX:
+			dx += float64(e.Value)
+		case e.Type == evRel && e.Code == relY:
+			dy += float64(e.Value)
+		case e.Type == evSyn && e.Code == synReport:
+			if dx == 0 && dy == 0 {
+				continue
+			}
+			if len(motion) == 0 {
+				start = e.Time
+			}
+			motion = append(motion, motionEvent{Time: e.Time - start, DX: dx, DY: dy})
+			dx, dy = 0, 0
+		}
+	}
+	return motion
+}
+
+// Median interval between reports during continuous motion, in Hz
+func estimatePollingRate(events []motionEvent) float64 {
+	var intervals []float64
+	for i := 1; i < len(events); i++ {
+		dt := events[i].Time - events[i-1].Time
+		if dt > 0 && dt <= traceIdle {
+			intervals = append(intervals, dt.Seconds())
+		}
+	}
+	if len(intervals) == 0 {
+		return 0
+	}
+	sort.Float64s(intervals)
+	return 1 / intervals[len(intervals)/2]
+}
+
+// Read a device until stop is closed
+func recordEvdev(path string, stop <-chan struct{}) ([]motionEvent, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	type result struct {
+		events []evdevEvent
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		events, err := readEvdevEvents(file)
+		done <- result{events, err}
+	}()
+	select {
+	case <-stop:
+		// Closing the device ends the pending read
+		file.Close()
+		res := <-done
+		return evdevMotion(res.events), nil
+	case res := <-done:
+		file.Close()
+		return evdevMotion(res.events), res.err
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+	"time"
+)
+
+func evdevDump(events []evdevEvent) []byte {
+	var buf bytes.Buffer
+	for _, e := range events {
+		_ = binary.Write(&buf, binary.LittleEndian, int64(e.Time/time.Second))
+		_ = binary.Write(&buf, binary.LittleEndian, int64(e.Time%time.Second/time.Microsecond))
+		_ = binary.Write(&buf, binary.LittleEndian, e.Type)
+		_ = binary.Write(&buf, binary.LittleEndian, e.Code)
+		_ = binary.Write(&buf, binary.LittleEndian, e.Value)
+	}
+	return buf.Bytes()
+}
+
+// Frames of 4 counts right and 3 down every millisecond
+func evdevFrames(n int) []evdevEvent {
+	var events []evdevEvent
+	start := 1700000000 * time.Second
+	for i := 0; i < n; i++ {
+		t := start + time.Duration(i)*time.Millisecond
+		events = append(events,
+			evdevEvent{Time: t, Type: evRel, Code: relX, Value: 4},
+			evdevEvent{Time: t, Type: evRel, Code: relY, Value: 3},
+			evdevEvent{Time: t, Type: evSyn, Code: synReport},
+		)
+	}
+	return events
+}
+
+func TestReadEvdevDump(t *testing.T) {
+	events, err := readEvdevFile(bytes.NewReader(evdevDump(evdevFrames(10))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	motion := evdevMotion(events)
+	if len(motion) != 10 {
+		t.Fatalf("got %d motion events, want 10", len(motion))
+	}
+	if motion[9].Time != 9*time.Millisecond || motion[9].DX != 4 || motion[9].DY != 3 {
+		t.Errorf("last event = %+v", motion[9])
+	}
+	for _, s := range traceSpeeds(motion) {
+		if !almostEqual(s.Speed, 5) {
+			t.Errorf("speed = %v counts/ms, want 5", s.Speed)
+		}
+	}
+	if rate := estimatePollingRate(motion); !almostEqual(rate, 1000) {
+		t.Errorf("polling rate = %v, want 1000", rate)
+	}
+}
+
+func TestReadEvdevDumpTruncated(t *testing.T) {
+	dump := evdevDump(evdevFrames(2))
+	if _, err := readEvdevFile(bytes.NewReader(dump[:len(dump)-3])); err == nil {
+		t.Error("expected an error for a truncated dump")
+	}
+}
+
+func TestParseEvtestLog(t *testing.T) {
+	log := `Input driver version is 1.0.1
+Event: time 1700000000.000000, type 2 (EV_REL), code 0 (REL_X), value 2
+Event: time 1700000000.000000, type 2 (EV_REL), code 1 (REL_Y), value -1
+Event: time 1700000000.000000, -------------- SYN_REPORT ------------
+Event: time 1700000000.000125, type 2 (EV_REL), code 0 (REL_X), value 3
+Event: time 1700000000.000125, -------------- SYN_REPORT ------------
+Event: time 1700000000.000250, type 2 (EV_REL), code 0 (REL_X), value 3
+Event: time 1700000000.000250, -------------- SYN_REPORT ------------
+`
+	events, err := readEvdevFile(strings.NewReader(log))
+	if err != nil {
+		t.Fatal(err)
+	}
+	motion := evdevMotion(events)
+	if len(motion) != 3 || motion[0].DX != 2 || motion[0].DY != -1 || motion[1].Time != 125*time.Microsecond {
+		t.Errorf("motion = %+v", motion)
+	}
+	if rate := estimatePollingRate(motion); !almostEqual(rate, 8000) {
+		t.Errorf("polling rate = %v, want 8000", rate)
+	}
+}
+
+func TestParseLibinputRecord(t *testing.T) {
+	log := `events:
+- evdev:
+  - [  0,    500,   2,   0,       1] # EV_REL / REL_X                    1
+  - [  0,    500,   0,   0,       0] # ------------ SYN_REPORT (0) ---------- +0ms
+- evdev:
+  - [  0,   1500,   2,   1,      -2] # EV_REL / REL_Y                   -2
+  - [  0,   1500,   0,   0,       0] # ------------ SYN_REPORT (0) ---------- +1ms
+`
+	events, err := readEvdevFile(strings.NewReader(log))
+	if err != nil {
+		t.Fatal(err)
+	}
+	motion := evdevMotion(events)
+	if len(motion) != 2 || motion[0].DX != 1 || motion[1].DY != -2 || motion[1].Time != time.Millisecond {
+		t.Errorf("motion = %+v", motion)
+	}
+}
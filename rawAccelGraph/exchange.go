@@ -0,0 +1,221 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	formatCSV  = "csv"
+	formatJSON = "json"
+)
+
+type csvOptions struct {
+	Delimiter    rune
+	DecimalComma bool
+}
+
+func writeCSV(w io.Writer, points []curvePoint, opts csvOptions) error {
+	writer := csv.NewWriter(w)
+	writer.Comma = opts.Delimiter
+	if err := writer.Write([]string{"x", "y"}); err != nil {
+		return err
+	}
+	for _, p := range points {
+		x := strconv.FormatFloat(p.X, 'f', -1, 64)
+		y := strconv.FormatFloat(p.Y, 'f', -1, 64)
+		if opts.DecimalComma {
+			x = strings.Replace(x, ".", ",", 1)
+			y = strings.Replace(y, ".", ",", 1)
+		}
+		if err := writer.Write([]string{x, y}); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// The header line is optional: a first record that does not start with a
+// number is skipped, any later one is an error. Errors give the line in the file.
+func readCSV(r io.Reader, opts csvOptions) ([]curvePoint, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = opts.Delimiter
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	var points []curvePoint
+	for first := true; ; first = false {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) < 2 {
+			continue
+		}
+		x, errX := parseDecimal(record[0], opts.DecimalComma)
+		y, errY := parseDecimal(record[1], opts.DecimalComma)
+		if errX != nil {
+			if first {
+				continue
+			}
+			line, _ := reader.FieldPos(0)
+			return nil, fmt.Errorf("line %d: %w", line, errX)
+		}
+		if errY != nil {
+			line, _ := reader.FieldPos(1)
+			return nil, fmt.Errorf("line %d: %w", line, errY)
+		}
+		points = append(points, curvePoint{X: x, Y: y})
+	}
+	return points, checkPoints(points)
+}
+
+func parseDecimal(s string, decimalComma bool) (float64, error) {
+	s = strings.TrimSpace(s)
+	if decimalComma {
+		s = strings.Replace(s, ",", ".", 1)
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+func writeJSON(w io.Writer, points []curvePoint) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if points == nil {
+		points = []curvePoint{}
+	}
+	return encoder.Encode(points)
+}
+
+func readJSON(r io.Reader) ([]curvePoint, error) {
+	var points []curvePoint
+	if err := json.NewDecoder(r).Decode(&points); err != nil {
+		return nil, err
+	}
+	return points, checkPoints(points)
+}
+
+func checkPoints(points []curvePoint) error {
+	if len(points) < 2 {
+		return fmt.Errorf("at least two points are needed")
+	}
+	for i := 1; i < len(points); i++ {
+		if points[i].X <= points[i-1].X {
+			return fmt.Errorf("x values must be increasing (point %d)", i+1)
+		}
+	}
+	return nil
+}
+
+func writePoints(w io.Writer, points []curvePoint, format string, opts csvOptions) error {
+	switch format {
+	case formatCSV:
+		return writeCSV(w, points, opts)
+	case formatJSON:
+		return writeJSON(w, points)
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
+
+func readPoints(r io.Reader, format string, opts csvOptions) ([]curvePoint, error) {
+	switch format {
+	case formatCSV:
+		return readCSV(r, opts)
+	case formatJSON:
+		return readJSON(r)
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+// Profile whose slider grid is the imported abscissas, one column per point
+func pointsConfig(base Config, points []curvePoint) Config {
+	grid := make([]float64, len(points))
+	for i, p := range points {
+		grid[i] = p.X
+	}
+	return regridConfig(base, points, distinctGrid(grid))
+}
+
+func delimiterRune(name string) rune {
+	switch name {
+	case "tab", "\\t":
+		return '\t'
+	case "":
+		return ','
+	}
+	return []rune(name)[0]
+}
+
+// Delimiter and decimal comma settings, asked before the file dialogs
+func askCSVOptions(next func(csvOptions)) {
+	delimiter := widget.NewSelect([]string{",", ";", "tab"}, nil)
+	delimiter.SetSelected(",")
+	decimalComma := widget.NewCheck("Decimal comma (1,5)", func(checked bool) {
+		if checked && delimiter.Selected == "," {
+			delimiter.SetSelected(";")
+		}
+	})
+	items := []*widget.FormItem{
+		widget.NewFormItem("Delimiter", delimiter),
+		widget.NewFormItem("", decimalComma),
+	}
+	dialog.ShowForm("CSV options", "Continue", "Cancel", items, func(ok bool) {
+		if ok {
+			next(csvOptions{Delimiter: delimiterRune(delimiter.Selected), DecimalComma: decimalComma.Checked})
+		}
+	}, fyneApp.Window)
+}
+
+func importCurve(format string) {
+	open := func(opts csvOptions) {
+		dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil || reader == nil {
+				errorDialog(err)
+				return
+			}
+			defer reader.Close()
+			points, err := readPoints(reader, format, opts)
+			if err != nil {
+				errorDialog(err)
+				return
+			}
+			openProfile(pointsConfig(currentConfig(), points), filepath.Base(reader.URI().Path())+" (unsaved)")
+		}, fyneApp.Window)
+	}
+	if format == formatCSV {
+		askCSVOptions(open)
+		return
+	}
+	open(csvOptions{})
+}
+
+func exportCurve(format string) {
+	save := func(opts csvOptions) {
+		dialog.ShowFileSave(func(writer fyne.URIWriteCloser, err error) {
+			if err != nil || writer == nil {
+				errorDialog(err)
+				return
+			}
+			defer writer.Close()
+			errorDialog(writePoints(writer, currentCurve(), format, opts))
+		}, fyneApp.Window)
+	}
+	if format == formatCSV {
+		askCSVOptions(save)
+		return
+	}
+	save(csvOptions{})
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteCSV(t *testing.T) {
+	points := []curvePoint{{X: 1, Y: 1.5}, {X: 12.25, Y: 2}}
+	tests := []struct {
+		opts csvOptions
+		want string
+	}{
+		{csvOptions{Delimiter: ','}, "x,y\n1,1.5\n12.25,2\n"},
+		{csvOptions{Delimiter: ';', DecimalComma: true}, "x;y\n1;1,5\n12,25;2\n"},
+		{csvOptions{Delimiter: '\t'}, "x\ty\n1\t1.5\n12.25\t2\n"},
+		// The writer quotes the fields holding the delimiter
+		{csvOptions{Delimiter: ',', DecimalComma: true}, "x,y\n1,\"1,5\"\n\"12,25\",2\n"},
+	}
+	for _, test := range tests {
+		var buf bytes.Buffer
+		if err := writeCSV(&buf, points, test.opts); err != nil {
+			t.Errorf("%+v: %v", test.opts, err)
+			continue
+		}
+		if buf.String() != test.want {
+			t.Errorf("%+v: got %q, want %q", test.opts, buf.String(), test.want)
+		}
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		opts csvOptions
+		want []curvePoint
+	}{
+		{"header", "x,y\n1,1\n10,1.5\n", csvOptions{Delimiter: ','}, []curvePoint{{X: 1, Y: 1}, {X: 10, Y: 1.5}}},
+		{"no header", "1,1\n10,1.5\n", csvOptions{Delimiter: ','}, []curvePoint{{X: 1, Y: 1}, {X: 10, Y: 1.5}}},
+		{"other header", "speed;sens\n1;1\n10;1.5\n", csvOptions{Delimiter: ';'}, []curvePoint{{X: 1, Y: 1}, {X: 10, Y: 1.5}}},
+		{"decimal comma", "x;y\n1,5;1\n10;1,25\n", csvOptions{Delimiter: ';', DecimalComma: true}, []curvePoint{{X: 1.5, Y: 1}, {X: 10, Y: 1.25}}},
+		{"quoted decimal comma", "1,\"1,5\"\n2,2\n", csvOptions{Delimiter: ',', DecimalComma: true}, []curvePoint{{X: 1, Y: 1.5}, {X: 2, Y: 2}}},
+		{"tab", "x\ty\n1\t1\n10\t2\n", csvOptions{Delimiter: '\t'}, []curvePoint{{X: 1, Y: 1}, {X: 10, Y: 2}}},
+		{"comments and spaces", "# from a spreadsheet\n1, 1\n\n10, 2, extra\n", csvOptions{Delimiter: ','}, []curvePoint{{X: 1, Y: 1}, {X: 10, Y: 2}}},
+	}
+	for _, test := range tests {
+		points, err := readCSV(strings.NewReader(test.src), test.opts)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if len(points) != len(test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, points, test.want)
+			continue
+		}
+		for i := range points {
+			if points[i] != test.want[i] {
+				t.Errorf("%s: point %d = %v, want %v", test.name, i, points[i], test.want[i])
+			}
+		}
+	}
+}
+
+func TestReadCSVErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		msg  string
+	}{
+		{"text after the header", "x,y\n1,1\nten,2\n", "line 3"},
+		{"bad sensitivity", "1,1\n2,fast\n", "line 2"},
+		{"single point", "x,y\n1,1\n", "two points"},
+		{"decreasing", "1,1\n5,1\n3,1\n", "increasing"},
+		{"wrong delimiter", "1;1\n2;1\n", "two points"},
+		// Comments and blank lines still count in the line numbers
+		{"line after comments", "# exported curve\n\nx,y\n1,1\n# fast part\n10,fast\n", "line 6"},
+		{"header after the first line", "1,1\nx,y\n", "line 2"},
+	}
+	for _, test := range tests {
+		_, err := readCSV(strings.NewReader(test.src), csvOptions{Delimiter: ','})
+		if err == nil || !strings.Contains(err.Error(), test.msg) {
+			t.Errorf("%s: got %v, want an error with %q", test.name, err, test.msg)
+		}
+	}
+}
+
+func TestCSVRoundTrip(t *testing.T) {
+	points := []curvePoint{{X: 1, Y: 1}, {X: 3.5, Y: 1.125}, {X: 40, Y: 2.75}}
+	for _, opts := range []csvOptions{{Delimiter: ','}, {Delimiter: ';', DecimalComma: true}, {Delimiter: '\t'}, {Delimiter: ',', DecimalComma: true}} {
+		var buf bytes.Buffer
+		if err := writePoints(&buf, points, formatCSV, opts); err != nil {
+			t.Fatal(err)
+		}
+		got, err := readPoints(&buf, formatCSV, opts)
+		if err != nil {
+			t.Errorf("%+v: %v", opts, err)
+			continue
+		}
+		for i := range points {
+			if got[i] != points[i] {
+				t.Errorf("%+v: point %d = %v, want %v", opts, i, got[i], points[i])
+			}
+		}
+	}
+}
+
+func TestJSON(t *testing.T) {
+	points := []curvePoint{{X: 1, Y: 1}, {X: 3.5, Y: 1.125}}
+	var buf bytes.Buffer
+	if err := writePoints(&buf, points, formatJSON, csvOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	want := "[\n  {\n    \"x\": 1,\n    \"y\": 1\n  },\n  {\n    \"x\": 3.5,\n    \"y\": 1.125\n  }\n]\n"
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+	got, err := readPoints(&buf, formatJSON, csvOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0] != points[0] || got[1] != points[1] {
+		t.Errorf("got %v, want %v", got, points)
+	}
+
+	buf.Reset()
+	if err := writeJSON(&buf, nil); err != nil || buf.String() != "[]\n" {
+		t.Errorf("empty curve: got %q, %v", buf.String(), err)
+	}
+	for _, src := range []string{"[{\"x\": 1, \"y\": 1}]", "{\"x\": 1}", "[{\"x\": 2, \"y\": 1}, {\"x\": 1, \"y\": 1}]"} {
+		if _, err := readJSON(strings.NewReader(src)); err == nil {
+			t.Errorf("%s: no error", src)
+		}
+	}
+	if _, err := readPoints(strings.NewReader(""), "xml", csvOptions{}); err == nil {
+		t.Error("no error for an unknown format")
+	}
+}
+
+func TestDelimiterRune(t *testing.T) {
+	tests := map[string]rune{
+		"":    ',',
+		",":   ',',
+		";":   ';',
+		"tab": '\t',
+		"\\t": '\t',
+		"|":   '|',
+	}
+	for name, want := range tests {
+		if got := delimiterRune(name); got != want {
+			t.Errorf("delimiterRune(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestImportNonUniformGrid(t *testing.T) {
+	points := []curvePoint{{X: 1, Y: 1}, {X: 3, Y: 1.1}, {X: 8, Y: 1.4}, {X: 20, Y: 1.9}, {X: 64, Y: 2.5}}
+	base := Config{ConfOrdonneesMin: "0", ConfOrdonneesMax: "3", ConfDPI: "800"}
+	cfg := pointsConfig(base, points)
+	if len(cfg.ConfGrid) != len(points) {
+		t.Fatalf("grid %v, want the imported abscissas", cfg.ConfGrid)
+	}
+
+	// Export and import again
+	var buf bytes.Buffer
+	if err := writePoints(&buf, configCurve(cfg), formatCSV, csvOptions{Delimiter: ','}); err != nil {
+		t.Fatal(err)
+	}
+	read, err := readPoints(&buf, formatCSV, csvOptions{Delimiter: ','})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := configCurve(pointsConfig(base, read))
+	if len(got) != len(points) {
+		t.Fatalf("got %v, want %v", got, points)
+	}
+	for i := range points {
+		if !almostEqual(got[i].X, points[i].X) || !almostEqual(got[i].Y, points[i].Y) {
+			t.Errorf("point %d = %v, want %v", i, got[i], points[i])
+		}
+	}
+}
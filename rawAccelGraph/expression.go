@@ -0,0 +1,516 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Formula the sliders were filled from, evaluated again when the grid
+// changes. Moving a slider by hand detaches it.
+var curveExpression string
+
+// Speed unit x is written in, kept when the displayed unit changes
+var curveExpressionUnit string
+
+// Set while the sliders are filled from the expression
+var applyingExpression bool
+
+// Parse error, Pos is the 1-based character of the expression
+type exprError struct {
+	Pos int
+	Msg string
+}
+
+func (e *exprError) Error() string {
+	return fmt.Sprintf("position %d: %s", e.Pos, e.Msg)
+}
+
+// Compiled expression of x, conditions are 1 when true and 0 when false
+type curveExpr func(x float64) float64
+
+const (
+	tokEnd = iota
+	tokNumber
+	tokIdent
+	tokOp
+)
+
+type exprToken struct {
+	kind int
+	text string
+	num  float64
+	pos  int
+}
+
+var exprOperators = []string{"<=", ">=", "==", "!=", "&&", "||", "+", "-", "*", "/", "^", "(", ")", ",", "<", ">", "!"}
+
+func tokenizeExpr(src string) ([]exprToken, error) {
+	runes := []rune(src)
+	var tokens []exprToken
+	i := 0
+	for i < len(runes) {
+		r := runes[i]
+		start := i
+		switch {
+		case unicode.IsSpace(r):
+			i++
+			continue
+		case unicode.IsDigit(r) || r == '.':
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			// Exponent, only when digits follow so x*e stays a constant
+			if i < len(runes) && (runes[i] == 'e' || runes[i] == 'E') {
+				j := i + 1
+				if j < len(runes) && (runes[j] == '+' || runes[j] == '-') {
+					j++
+				}
+				if j < len(runes) && unicode.IsDigit(runes[j]) {
+					for j < len(runes) && unicode.IsDigit(runes[j]) {
+						j++
+					}
+					i = j
+				}
+			}
+			text := string(runes[start:i])
+			num, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				return nil, &exprError{start + 1, fmt.Sprintf("invalid number %q", text)}
+			}
+			tokens = append(tokens, exprToken{kind: tokNumber, text: text, num: num, pos: start + 1})
+			continue
+		case unicode.IsLetter(r) || r == '_':
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, exprToken{kind: tokIdent, text: string(runes[start:i]), pos: start + 1})
+			continue
+		}
+		op := ""
+		for _, o := range exprOperators {
+			if strings.HasPrefix(string(runes[i:]), o) {
+				op = o
+				break
+			}
+		}
+		if op == "" {
+			return nil, &exprError{start + 1, fmt.Sprintf("unexpected character %q", r)}
+		}
+		i += len([]rune(op))
+		tokens = append(tokens, exprToken{kind: tokOp, text: op, pos: start + 1})
+	}
+	return append(tokens, exprToken{kind: tokEnd, pos: len(runes) + 1}), nil
+}
+
+type exprParser struct {
+	tokens []exprToken
+	next   int
+}
+
+func (p *exprParser) peek() exprToken {
+	return p.tokens[p.next]
+}
+
+func (p *exprParser) accept(op string) bool {
+	if t := p.peek(); t.kind == tokOp && t.text == op {
+		p.next++
+		return true
+	}
+	return false
+}
+
+func (p *exprParser) unexpected() error {
+	t := p.peek()
+	if t.kind == tokEnd {
+		return &exprError{t.pos, "unexpected end of expression"}
+	}
+	return &exprError{t.pos, fmt.Sprintf("unexpected %q", t.text)}
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// Operators from the loosest to the tightest binding
+var exprLevels = [][]string{
+	{"||"},
+	{"&&"},
+	{"<", "<=", ">", ">=", "==", "!="},
+	{"+", "-"},
+	{"*", "/"},
+}
+
+func binaryExpr(op string, a, b curveExpr) curveExpr {
+	switch op {
+	case "||":
+		return func(x float64) float64 { return boolValue(a(x) != 0 || b(x) != 0) }
+	case "&&":
+		return func(x float64) float64 { return boolValue(a(x) != 0 && b(x) != 0) }
+	case "<":
+		return func(x float64) float64 { return boolValue(a(x) < b(x)) }
+	case "<=":
+		return func(x float64) float64 { return boolValue(a(x) <= b(x)) }
+	case ">":
+		return func(x float64) float64 { return boolValue(a(x) > b(x)) }
+	case ">=":
+		return func(x float64) float64 { return boolValue(a(x) >= b(x)) }
+	case "==":
+		return func(x float64) float64 { return boolValue(a(x) == b(x)) }
+	case "!=":
+		return func(x float64) float64 { return boolValue(a(x) != b(x)) }
+	case "+":
+		return func(x float64) float64 { return a(x) + b(x) }
+	case "-":
+		return func(x float64) float64 { return a(x) - b(x) }
+	case "*":
+		return func(x float64) float64 { return a(x) * b(x) }
+	case "/":
+		return func(x float64) float64 { return a(x) / b(x) }
+	}
+	return func(x float64) float64 { return math.Pow(a(x), b(x)) }
+}
+
+func (p *exprParser) binary(level int) (curveExpr, error) {
+	if level == len(exprLevels) {
+		return p.unary()
+	}
+	left, err := p.binary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		matched := false
+		for _, op := range exprLevels[level] {
+			if t.kind == tokOp && t.text == op {
+				matched = true
+			}
+		}
+		if !matched {
+			return left, nil
+		}
+		p.next++
+		right, err := p.binary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = binaryExpr(t.text, left, right)
+	}
+}
+
+// -x^2 is -(x^2) and 2^3^2 is 2^(3^2)
+func (p *exprParser) unary() (curveExpr, error) {
+	if p.accept("-") {
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return func(x float64) float64 { return -operand(x) }, nil
+	}
+	if p.accept("!") {
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return func(x float64) float64 { return boolValue(operand(x) == 0) }, nil
+	}
+	base, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	if !p.accept("^") {
+		return base, nil
+	}
+	exponent, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	return binaryExpr("^", base, exponent), nil
+}
+
+var exprConstants = map[string]float64{
+	"pi": math.Pi,
+	"e":  math.E,
+}
+
+// Functions taking a fixed number of arguments
+var exprFunctions = map[string]struct {
+	args int
+	fn   func(a []float64) float64
+}{
+	"abs":   {1, func(a []float64) float64 { return math.Abs(a[0]) }},
+	"sqrt":  {1, func(a []float64) float64 { return math.Sqrt(a[0]) }},
+	"exp":   {1, func(a []float64) float64 { return math.Exp(a[0]) }},
+	"log":   {1, func(a []float64) float64 { return math.Log(a[0]) }},
+	"log10": {1, func(a []float64) float64 { return math.Log10(a[0]) }},
+	"tanh":  {1, func(a []float64) float64 { return math.Tanh(a[0]) }},
+	"floor": {1, func(a []float64) float64 { return math.Floor(a[0]) }},
+	"ceil":  {1, func(a []float64) float64 { return math.Ceil(a[0]) }},
+	"pow":   {2, func(a []float64) float64 { return math.Pow(a[0], a[1]) }},
+	"clamp": {3, func(a []float64) float64 { return math.Min(math.Max(a[0], a[1]), a[2]) }},
+}
+
+func (p *exprParser) primary() (curveExpr, error) {
+	t := p.peek()
+	switch {
+	case t.kind == tokNumber:
+		p.next++
+		return func(float64) float64 { return t.num }, nil
+	case t.kind == tokOp && t.text == "(":
+		p.next++
+		inner, err := p.binary(0)
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, &exprError{p.peek().pos, "expected ')'"}
+		}
+		return inner, nil
+	case t.kind == tokIdent:
+		p.next++
+		name := strings.ToLower(t.text)
+		if p.peek().kind == tokOp && p.peek().text == "(" {
+			return p.call(name, t.pos)
+		}
+		if name == "x" {
+			return func(x float64) float64 { return x }, nil
+		}
+		if value, ok := exprConstants[name]; ok {
+			return func(float64) float64 { return value }, nil
+		}
+		return nil, &exprError{t.pos, fmt.Sprintf("unknown variable %q", t.text)}
+	}
+	return nil, p.unexpected()
+}
+
+func (p *exprParser) call(name string, pos int) (curveExpr, error) {
+	p.accept("(")
+	var args []curveExpr
+	if !p.accept(")") {
+		for {
+			arg, err := p.binary(0)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if p.accept(")") {
+				break
+			}
+			if !p.accept(",") {
+				return nil, &exprError{p.peek().pos, "expected ',' or ')'"}
+			}
+		}
+	}
+
+	switch name {
+	case "min", "max":
+		if len(args) == 0 {
+			return nil, &exprError{pos, name + " needs at least one argument"}
+		}
+		pick := math.Min
+		if name == "max" {
+			pick = math.Max
+		}
+		return func(x float64) float64 {
+			result := args[0](x)
+			for _, arg := range args[1:] {
+				result = pick(result, arg(x))
+			}
+			return result
+		}, nil
+	case "if":
+		if len(args) != 3 {
+			return nil, &exprError{pos, "if takes a condition, a value and a default"}
+		}
+		return func(x float64) float64 {
+			if args[0](x) != 0 {
+				return args[1](x)
+			}
+			return args[2](x)
+		}, nil
+	case "piecewise":
+		// Pairs of condition and value, the first true condition wins
+		if len(args)%2 == 0 {
+			return nil, &exprError{pos, "piecewise takes condition, value pairs and a default value"}
+		}
+		return func(x float64) float64 {
+			for i := 0; i+1 < len(args); i += 2 {
+				if args[i](x) != 0 {
+					return args[i+1](x)
+				}
+			}
+			return args[len(args)-1](x)
+		}, nil
+	}
+
+	f, ok := exprFunctions[name]
+	if !ok {
+		return nil, &exprError{pos, fmt.Sprintf("unknown function %q", name)}
+	}
+	if len(args) != f.args {
+		return nil, &exprError{pos, fmt.Sprintf("%s takes %d argument(s), got %d", name, f.args, len(args))}
+	}
+	return func(x float64) float64 {
+		values := make([]float64, len(args))
+		for i, arg := range args {
+			values[i] = arg(x)
+		}
+		return f.fn(values)
+	}, nil
+}
+
+// Only arithmetic on x, there is nothing an expression can reach outside of it
+func parseCurveExpr(src string) (curveExpr, error) {
+	tokens, err := tokenizeExpr(src)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 1 {
+		return nil, &exprError{1, "empty expression"}
+	}
+	p := &exprParser{tokens: tokens}
+	expr, err := p.binary(0)
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokEnd {
+		return nil, p.unexpected()
+	}
+	return expr, nil
+}
+
+// Sensitivity at every key, x is the speed in the given unit
+func evaluateExpr(expr curveExpr, keys []float64, unit string, dpi float64) ([]curvePoint, error) {
+	factor := unitFactor(unit, dpi)
+	points := make([]curvePoint, len(keys))
+	for i, key := range keys {
+		y := expr(key * factor)
+		if math.IsNaN(y) || math.IsInf(y, 0) {
+			return nil, fmt.Errorf("no value at x = %s %s", formatFloat(key*factor, 3), unit)
+		}
+		points[i] = curvePoint{X: key, Y: y}
+	}
+	return points, nil
+}
+
+// Expression with a caret under the failing character
+func exprErrorText(src string, err error) string {
+	e, ok := err.(*exprError)
+	if !ok {
+		return err.Error()
+	}
+	line, column := 0, e.Pos
+	lines := strings.Split(src, "\n")
+	for line < len(lines)-1 && column > len([]rune(lines[line]))+1 {
+		column -= len([]rune(lines[line])) + 1
+		line++
+	}
+	return fmt.Sprintf("%s\n%s^\n%s", lines[line], strings.Repeat(" ", column-1), e.Error())
+}
+
+// Fill the sliders from the expression of the profile, after the grid was rebuilt
+func applyExpression() {
+	if curveExpression == "" {
+		return
+	}
+	expr, err := parseCurveExpr(curveExpression)
+	if err != nil {
+		errorDialog(err)
+		return
+	}
+	unit := curveExpressionUnit
+	if unit == "" {
+		unit = displayedUnit
+	}
+	points, err := evaluateExpr(expr, sliderKeys(), unit, displayedDPI)
+	if err != nil {
+		errorDialog(err)
+		return
+	}
+	applyingExpression = true
+	applyCurve(curveValues(points))
+	applyingExpression = false
+}
+
+func showExpressionDialog() {
+	points := currentCurve()
+	if len(points) == 0 {
+		return
+	}
+	entry := widget.NewMultiLineEntry()
+	entry.TextStyle = fyne.TextStyle{Monospace: true}
+	entry.Text = curveExpression
+	if entry.Text == "" {
+		entry.Text = "1 + 0.015 * max(x - 4, 0)^1.3"
+	}
+	// An existing expression keeps the unit it was written in
+	unit := displayedUnit
+	if curveExpression != "" && curveExpressionUnit != "" {
+		unit = curveExpressionUnit
+	}
+	status := widget.NewLabel("")
+	status.TextStyle = fyne.TextStyle{Monospace: true}
+	help := widget.NewLabel("x is the speed in " + unit + ". Operators + - * / ^ < <= > >= == != && || !,\n" +
+		"functions abs sqrt exp log log10 tanh floor ceil pow min max clamp,\n" +
+		"if(condition, value, default) and piecewise(c1, v1, c2, v2, ..., default).")
+	chart := newCurveChart()
+
+	var preview []curvePoint
+	update := func() {
+		preview = nil
+		expr, err := parseCurveExpr(entry.Text)
+		if err == nil {
+			preview, err = evaluateExpr(expr, sliderKeys(), unit, displayedDPI)
+		}
+		if err != nil {
+			status.SetText(exprErrorText(entry.Text, err))
+			chart.SetSeries(chartSeries{Points: points, Color: theme.DisabledColor(), Width: 1})
+			return
+		}
+		min, max := ui.Sliders[points[0].X].Min, ui.Sliders[points[0].X].Max
+		clipped := 0
+		for _, p := range preview {
+			if p.Y < min || p.Y > max {
+				clipped++
+			}
+		}
+		status.SetText("")
+		if clipped > 0 {
+			status.SetText(fmt.Sprintf("%d point(s) outside of %s-%s are clipped", clipped, formatFloat(min, 2), formatFloat(max, 2)))
+		}
+		chart.SetSeries(
+			chartSeries{Points: points, Color: theme.DisabledColor(), Width: 1},
+			chartSeries{Points: preview, Color: theme.PrimaryColor(), Width: 2},
+		)
+	}
+	entry.OnChanged = func(string) { update() }
+	update()
+
+	content := container.NewBorder(container.NewVBox(help, entry, status), nil, nil, nil, chart)
+	exprDial := dialog.NewCustomConfirm("Curve expression", "Apply", "Cancel", content, func(ok bool) {
+		if !ok {
+			return
+		}
+		if preview == nil {
+			errorDialog(fmt.Errorf("the expression is not valid"))
+			return
+		}
+		curveExpression = strings.TrimSpace(entry.Text)
+		curveExpressionUnit = unit
+		applyExpression()
+	}, fyneApp.Window)
+	exprDial.Resize(fyne.NewSize(600, 550))
+	exprDial.Show()
+}
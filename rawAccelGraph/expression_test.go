@@ -0,0 +1,98 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestParseCurveExpr(t *testing.T) {
+	tests := []struct {
+		src  string
+		x    float64
+		want float64
+	}{
+		{"1 + 0.015 * max(x - 4, 0)^1.3", 2, 1},
+		{"1 + 0.015 * max(x - 4, 0)^1.3", 14, 1 + 0.015*math.Pow(10, 1.3)},
+		{"-x^2", 3, -9},
+		{"2^3^2", 0, 512},
+		{"(1 + 2) * 3 - 4 / 2", 0, 7},
+		{"1.5e-1 * x", 10, 1.5},
+		{"x*e", 1, math.E},
+		{"if(x < 10, 1, 1.5)", 12, 1.5},
+		{"piecewise(x < 4, 1, x < 10, 1 + 0.1 * (x - 4), 1.6)", 6, 1.2},
+		{"piecewise(x < 4, 1, x < 10, 1 + 0.1 * (x - 4), 1.6)", 20, 1.6},
+		{"x >= 2 && x <= 4 || !(x != 10)", 10, 1},
+		{"clamp(x / 10, 1, 2)", 50, 2},
+		{"Min(3, X, 5)", 4, 3},
+	}
+	for _, test := range tests {
+		expr, err := parseCurveExpr(test.src)
+		if err != nil {
+			t.Errorf("%q: %v", test.src, err)
+			continue
+		}
+		if got := expr(test.x); !almostEqual(got, test.want) {
+			t.Errorf("%q at %v = %v, want %v", test.src, test.x, got, test.want)
+		}
+	}
+}
+
+func TestParseCurveExprErrors(t *testing.T) {
+	tests := []struct {
+		src string
+		pos int
+		msg string
+	}{
+		{"1 + ", 5, "unexpected end"},
+		{"1 + y", 5, "unknown variable"},
+		{"2 * (x + 1", 11, "expected ')'"},
+		{"1 $ 2", 3, "unexpected character"},
+		{"foo(x)", 1, "unknown function"},
+		{"pow(x)", 1, "takes 2 argument"},
+		{"piecewise(x < 1, 2)", 1, "default value"},
+		{"1 2", 3, "unexpected \"2\""},
+		{"1.2.3", 1, "invalid number"},
+		{"  ", 1, "empty"},
+	}
+	for _, test := range tests {
+		_, err := parseCurveExpr(test.src)
+		e, ok := err.(*exprError)
+		if !ok {
+			t.Errorf("%q: got %v, want a position error", test.src, err)
+			continue
+		}
+		if e.Pos != test.pos || !strings.Contains(e.Msg, test.msg) {
+			t.Errorf("%q: got %v, want position %d and %q", test.src, e, test.pos, test.msg)
+		}
+	}
+}
+
+func TestEvaluateExprUnit(t *testing.T) {
+	expr, err := parseCurveExpr("x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 8 counts/ms at 800 DPI is 10 in/s
+	points, err := evaluateExpr(expr, []float64{8}, unitInchS, 800)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !almostEqual(points[0].Y, 10) || points[0].X != 8 {
+		t.Errorf("got %v, want {8 10}", points[0])
+	}
+
+	expr, _ = parseCurveExpr("log(x - 5)")
+	if _, err := evaluateExpr(expr, []float64{1, 10}, unitCountsMs, 800); err == nil {
+		t.Error("no error for log of a negative number")
+	}
+}
+
+func TestExprErrorText(t *testing.T) {
+	src := "piecewise(x < 4, 1,\n  x < 10, z, 2)"
+	_, err := parseCurveExpr(src)
+	want := "  x < 10, z, 2)\n          ^\nposition 31: unknown variable \"z\""
+	if got := exprErrorText(src, err); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Raw Accel mode expressed as sensitivity at a given input speed (counts/ms)
+type accelMode struct {
+	Name    string
+	Params  []string
+	Initial []float64
+	// Parameters only used by absolute value, reported positive
+	Abs []int
+	// Lower bounds Sens applies to parameters, reported clamped
+	Min  map[int]float64
+	Sens func(x float64, p []float64) float64
+}
+
+type fitResult struct {
+	Mode   accelMode
+	Params []float64
+	RMS    float64
+}
+
+var accelModes = []accelMode{
+	{
+		Name:    "Classic",
+		Params:  []string{"Acceleration", "Input offset", "Exponent", "Sens multiplier"},
+		Initial: []float64{0.01, 0, 2, 1},
+		Abs:     []int{0},
+		Min:     map[int]float64{2: 1},
+		Sens: func(x float64, p []float64) float64 {
+			return p[3] * (1 + math.Pow(math.Abs(p[0])*math.Max(x-p[1], 0), math.Max(p[2], 1)-1))
+		},
+	},
+	{
+		Name:    "Natural",
+		Params:  []string{"Decay rate", "Input offset", "Limit", "Sens multiplier"},
+		Initial: []float64{0.1, 0, 2, 1},
+		Abs:     []int{0},
+		Sens: func(x float64, p []float64) float64 {
+			return p[3] * (1 + (p[2]-1)*(1-math.Exp(-math.Abs(p[0])*math.Max(x-p[1], 0))))
+		},
+	},
+	{
+		Name:    "Power",
+		Params:  []string{"Scale", "Exponent"},
+		Initial: []float64{1, 0.1},
+		Abs:     []int{0},
+		Sens: func(x float64, p []float64) float64 {
+			return math.Pow(math.Abs(p[0])*x, p[1])
+		},
+	},
+	{
+		Name:    "Motivity",
+		Params:  []string{"Growth rate", "Motivity", "Midpoint", "Sens multiplier"},
+		Initial: []float64{1, 1.5, 10, 1},
+		Abs:     []int{1, 2},
+		Sens: func(x float64, p []float64) float64 {
+			motivity := math.Max(math.Abs(p[1]), 1e-6)
+			midpoint := math.Max(math.Abs(p[2]), 1e-6)
+			logistic := 2/(1+math.Exp(-p[0]*(math.Log(math.Max(x, 1e-6))-math.Log(midpoint)))) - 1
+			return p[3] * math.Exp(math.Log(motivity)*logistic)
+		},
+	},
+}
+
+func fitAllModes(points []curvePoint) []fitResult {
+	var results []fitResult
+	for _, mode := range accelModes {
+		results = append(results, fitMode(mode, points))
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].RMS < results[j].RMS })
+	return results
+}
+
+func fitMode(mode accelMode, points []curvePoint) fitResult {
+	cost := func(p []float64) float64 {
+		sum := 0.0
+		for _, pt := range points {
+			d := mode.Sens(pt.X, p) - pt.Y
+			sum += d * d
+		}
+		if math.IsNaN(sum) || math.IsInf(sum, 0) {
+			return math.MaxFloat64
+		}
+		return sum
+	}
+	initial := append([]float64(nil), mode.Initial...)
+	// Start from the curve level so the multiplier converges faster
+	if len(points) > 0 && len(initial) == 4 {
+		initial[3] = math.Max(points[0].Y, 0.01)
+	}
+	params := nelderMead(cost, initial, 2000)
+	// Restart once from the solution to escape early collapse of the simplex
+	params = nelderMead(cost, params, 2000)
+	for _, i := range mode.Abs {
+		params[i] = math.Abs(params[i])
+	}
+	for i, min := range mode.Min {
+		params[i] = math.Max(params[i], min)
+	}
+	rms := 0.0
+	if len(points) > 0 {
+		rms = math.Sqrt(cost(params) / float64(len(points)))
+	}
+	return fitResult{Mode: mode, Params: params, RMS: rms}
+}
+
+// Downhill simplex minimization, no derivatives needed
+func nelderMead(f func([]float64) float64, start []float64, iterations int) []float64 {
+	n := len(start)
+	simplex := make([][]float64, n+1)
+	values := make([]float64, n+1)
+	simplex[0] = append([]float64(nil), start...)
+	for i := 0; i < n; i++ {
+		p := append([]float64(nil), start...)
+		if p[i] != 0 {
+			p[i] *= 1.5
+		} else {
+			p[i] = 0.5
+		}
+		simplex[i+1] = p
+	}
+	for i := range simplex {
+		values[i] = f(simplex[i])
+	}
+
+	move := func(from, to []float64, t float64) []float64 {
+		p := make([]float64, n)
+		for i := range p {
+			p[i] = from[i] + t*(to[i]-from[i])
+		}
+		return p
+	}
+
+	for it := 0; it < iterations; it++ {
+		sort.Sort(simplexSort{simplex, values})
+		if math.Abs(values[n]-values[0]) < 1e-12 {
+			break
+		}
+		centroid := make([]float64, n)
+		for _, p := range simplex[:n] {
+			for i := range centroid {
+				centroid[i] += p[i] / float64(n)
+			}
+		}
+		reflected := move(simplex[n], centroid, 2)
+		fr := f(reflected)
+		switch {
+		case fr < values[0]:
+			expanded := move(simplex[n], centroid, 3)
+			if fe := f(expanded); fe < fr {
+				simplex[n], values[n] = expanded, fe
+			} else {
+				simplex[n], values[n] = reflected, fr
+			}
+		case fr < values[n-1]:
+			simplex[n], values[n] = reflected, fr
+		default:
+			contracted := move(simplex[n], centroid, 0.5)
+			if fc := f(contracted); fc < values[n] {
+				simplex[n], values[n] = contracted, fc
+				continue
+			}
+			for i := 1; i <= n; i++ {
+				simplex[i] = move(simplex[0], simplex[i], 0.5)
+				values[i] = f(simplex[i])
+			}
+		}
+	}
+	sort.Sort(simplexSort{simplex, values})
+	return simplex[0]
+}
+
+type simplexSort struct {
+	points [][]float64
+	values []float64
+}
+
+func (s simplexSort) Len() int           { return len(s.values) }
+func (s simplexSort) Less(i, j int) bool { return s.values[i] < s.values[j] }
+func (s simplexSort) Swap(i, j int) {
+	s.points[i], s.points[j] = s.points[j], s.points[i]
+	s.values[i], s.values[j] = s.values[j], s.values[i]
+}
+
+func (r fitResult) curve(points []curvePoint) []curvePoint {
+	result := make([]curvePoint, len(points))
+	for i, p := range points {
+		result[i] = curvePoint{X: p.X, Y: r.Mode.Sens(p.X, r.Params)}
+	}
+	return result
+}
+
+func (r fitResult) String() string {
+	var params []string
+	for i, name := range r.Mode.Params {
+		params = append(params, fmt.Sprintf("%s: %s", name, formatFloat(r.Params[i], 4)))
+	}
+	return fmt.Sprintf("%s (RMS %s)\n%s", r.Mode.Name, formatFloat(r.RMS, 4), strings.Join(params, ", "))
+}
+
+func showFitDialog() {
+	points := currentCurve()
+	if len(points) < 2 {
+		return
+	}
+	results := fitAllModes(points)
+
+	var names []string
+	for _, r := range results {
+		names = append(names, r.Mode.Name)
+	}
+	var selected fitResult
+	detail := widget.NewLabel("")
+	chart := newCurveChart()
+	choice := widget.NewSelect(names, func(s string) {
+		for _, r := range results {
+			if r.Mode.Name == s {
+				selected = r
+			}
+		}
+		detail.SetText(selected.String())
+		chart.SetSeries(
+			chartSeries{Points: points, Color: theme.DisabledColor(), Width: 1},
+			chartSeries{Points: selected.curve(points), Color: theme.PrimaryColor(), Width: 2},
+		)
+	})
+	choice.SetSelected(names[0])
+
+	var summary []string
+	for _, r := range results {
+		summary = append(summary, r.Mode.Name+": RMS "+formatFloat(r.RMS, 4))
+	}
+
+	overlayBtn := widget.NewButton("Overlay on chart", func() {
+		setOverlay(chartSeries{Points: selected.curve(points), Color: overlayColor, Width: 2})
+	})
+	clearBtn := widget.NewButton("Clear overlay", func() {
+		setOverlay()
+	})
+	replaceBtn := widget.NewButton("Replace table", func() {
+		applyCurve(curveValues(selected.curve(points)))
+	})
+
+	content := container.NewVBox(
+		widget.NewLabel(strings.Join(summary, "\n")),
+		&widget.Separator{},
+		choice,
+		detail,
+		chart,
+		container.NewHBox(overlayBtn, clearBtn, replaceBtn),
+	)
+	fitDial := dialog.NewCustom("Fit Raw Accel mode", "Close", content, fyneApp.Window)
+	fitDial.Resize(fyne.NewSize(550, 550))
+	fitDial.Show()
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNelderMeadQuadratic(t *testing.T) {
+	f := func(p []float64) float64 {
+		return (p[0]-3)*(p[0]-3) + 2*(p[1]+1)*(p[1]+1)
+	}
+	got := nelderMead(f, []float64{0, 0}, 2000)
+	if math.Abs(got[0]-3) > 1e-4 || math.Abs(got[1]+1) > 1e-4 {
+		t.Errorf("minimum at %v, want [3 -1]", got)
+	}
+}
+
+func modeCurve(mode accelMode, params []float64) []curvePoint {
+	var points []curvePoint
+	for x := 1.0; x <= 60; x += 4 {
+		points = append(points, curvePoint{X: x, Y: mode.Sens(x, params)})
+	}
+	return points
+}
+
+func TestFitModeRecoversCurve(t *testing.T) {
+	for _, test := range []struct {
+		mode   int
+		params []float64
+	}{
+		{0, []float64{0.02, 2, 2, 1}},
+		{1, []float64{0.05, 0, 1.8, 1}},
+		{2, []float64{1, 0.15}},
+	} {
+		mode := accelModes[test.mode]
+		result := fitMode(mode, modeCurve(mode, test.params))
+		if result.RMS > 0.01 {
+			t.Errorf("%s: RMS %v with %v, want a close fit of %v", mode.Name, result.RMS, result.Params, test.params)
+		}
+	}
+}
+
+func TestFitModeClampsParameters(t *testing.T) {
+	// Decreasing curve, an exponent below 1 would fit it better but Classic never uses one
+	var points []curvePoint
+	for x := 1.0; x <= 60; x += 4 {
+		points = append(points, curvePoint{X: x, Y: 2 - x/100})
+	}
+	result := fitMode(accelModes[0], points)
+	if result.Params[2] < 1 {
+		t.Errorf("exponent %v reported below 1", result.Params[2])
+	}
+	if result.Params[0] < 0 {
+		t.Errorf("acceleration %v reported negative", result.Params[0])
+	}
+	// The reported parameters give the curve the fit measured
+	rms := 0.0
+	for _, p := range points {
+		d := accelModes[0].Sens(p.X, result.Params) - p.Y
+		rms += d * d
+	}
+	if math.Abs(math.Sqrt(rms/float64(len(points)))-result.RMS) > 1e-9 {
+		t.Errorf("RMS %v does not match the reported parameters", result.RMS)
+	}
+}
+
+func TestFitAllModesSorted(t *testing.T) {
+	results := fitAllModes(modeCurve(accelModes[1], []float64{0.05, 0, 1.8, 1}))
+	for i := 1; i < len(results); i++ {
+		if results[i].RMS < results[i-1].RMS {
+			t.Errorf("result %d RMS %v is below %v", i, results[i].RMS, results[i-1].RMS)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	gameQuakeLive      = "Quake Live"
+	gameQuakeChampions = "Quake Champions"
+	gameSource         = "Source m_customaccel"
+)
+
+var accelGames = []string{gameQuakeLive, gameQuakeChampions, gameSource}
+
+// In-game acceleration cvars. The game multiplies the counts by the
+// accelerated sensitivity, the lookup table divides it by Sens since the
+// game keeps applying its own sensitivity.
+type gameAccel struct {
+	Game string
+	Sens float64
+	// cl_mouseAccel, cl_mouseAccelOffset, cl_mouseAccelPower, cl_mouseSensCap
+	Accel   float64
+	Offset  float64
+	Power   float64
+	SensCap float64
+	// m_customaccel, m_customaccel_scale, m_customaccel_exponent, m_customaccel_max
+	CustomAccel int
+	Scale       float64
+	Exponent    float64
+	Max         float64
+	Yaw         float64
+	Pitch       float64
+	// Source measures the counts per frame, not per millisecond
+	FPS float64
+}
+
+func defaultGameAccel(game string) gameAccel {
+	return gameAccel{
+		Game:        game,
+		Sens:        2,
+		Accel:       0.1,
+		Power:       2,
+		CustomAccel: 1,
+		Scale:       0.04,
+		Exponent:    1.05,
+		Yaw:         0.022,
+		Pitch:       0.022,
+		FPS:         300,
+	}
+}
+
+func (g gameAccel) validate() error {
+	if g.Sens <= 0 {
+		return fmt.Errorf("sensitivity must be positive")
+	}
+	switch g.Game {
+	case gameQuakeLive, gameQuakeChampions:
+		if g.Accel < 0 || g.Offset < 0 || g.SensCap < 0 {
+			return fmt.Errorf("accel, offset and sens cap can't be negative")
+		}
+		if g.Power < 1 {
+			return fmt.Errorf("power must be at least 1")
+		}
+	case gameSource:
+		if g.CustomAccel < 0 || g.CustomAccel > 3 {
+			return fmt.Errorf("m_customaccel is 0, 1, 2 or 3")
+		}
+		if g.FPS <= 0 {
+			return fmt.Errorf("frame rate must be positive")
+		}
+		if g.Exponent < 0 {
+			return fmt.Errorf("exponent can't be negative")
+		}
+		if g.CustomAccel == 2 && (g.Yaw <= 0 || g.Pitch <= 0) {
+			return fmt.Errorf("m_yaw and m_pitch must be positive")
+		}
+	default:
+		return fmt.Errorf("unknown game %q", g.Game)
+	}
+	return nil
+}
+
+// Sensitivity multiplier at a speed in counts/ms
+func (g gameAccel) multiplier(speed float64) float64 {
+	switch g.Game {
+	case gameQuakeLive, gameQuakeChampions:
+		sens := g.Sens + math.Pow(math.Max(speed-g.Offset, 0)*g.Accel, g.Power-1)
+		if g.SensCap > 0 && sens > g.SensCap {
+			sens = g.SensCap
+		}
+		return sens / g.Sens
+	case gameSource:
+		distance := speed * 1000 / g.FPS
+		switch g.CustomAccel {
+		case 1, 2:
+			sens := math.Pow(distance, g.Exponent)*g.Scale + g.Sens
+			if g.Max > 0.0001 && sens > g.Max {
+				sens = g.Max
+			}
+			// Mode 2 scales again by m_yaw and m_pitch, the pitch goes to the Y/X ratio
+			if g.CustomAccel == 2 {
+				sens *= g.Yaw
+			}
+			return sens / g.Sens
+		case 3:
+			return math.Pow(distance*distance, math.Max(0, (g.Exponent-1)/2))
+		}
+	}
+	return 1
+}
+
+// Profile with the game curve on the grid of base
+func gameAccelConfig(base Config, g gameAccel, dpi float64) (Config, error) {
+	if err := g.validate(); err != nil {
+		return Config{}, err
+	}
+	if dpi <= 0 {
+		return Config{}, fmt.Errorf("DPI must be positive")
+	}
+	cfg := base
+	cfg.ConfDPI = formatFloat(dpi, 0)
+	cfg.ConfRawAccel = defaultRawAccelSettings()
+	cfg.ConfRawAccel.SeparateXY = false
+	if g.Game == gameSource && g.CustomAccel == 2 {
+		cfg.ConfRawAccel.YXRatio = g.Pitch / g.Yaw
+	}
+	cfg.ConfGraphY = nil
+	cfg.ConfYRatio = 0
+	points := configCurve(cfg)
+	for i := range points {
+		points[i].Y = g.multiplier(points[i].X)
+	}
+	return curveConfig(cfg, points), nil
+}
+
+func showGameAccelDialog() {
+	g := defaultGameAccel(gameQuakeLive)
+	dpi := settingsDPI()
+	status := widget.NewLabel("")
+	chart := newCurveChart()
+
+	var converted Config
+	var convertErr error
+	// Fields holding text that is not a number, Open refuses until they are fixed
+	invalid := map[*float64]error{}
+	update := func() {
+		converted, convertErr = gameAccelConfig(currentConfig(), g, dpi)
+		for _, err := range invalid {
+			convertErr = err
+		}
+		if convertErr != nil {
+			status.SetText(convertErr.Error())
+			chart.SetSeries()
+			return
+		}
+		status.SetText("")
+		chart.SetSeries(chartSeries{Points: configCurve(converted), Color: theme.PrimaryColor(), Width: 2})
+	}
+	field := func(value *float64) *widget.Entry {
+		entry := widget.NewEntry()
+		entry.Text = strconv.FormatFloat(*value, 'f', -1, 64)
+		entry.OnChanged = func(s string) {
+			v, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				invalid[value] = fmt.Errorf("invalid number: %s", s)
+			} else {
+				delete(invalid, value)
+				*value = v
+			}
+			update()
+		}
+		return entry
+	}
+	customAccel := widget.NewSelect([]string{"0", "1", "2", "3"}, func(s string) {
+		g.CustomAccel, _ = strconv.Atoi(s)
+		update()
+	})
+	customAccel.Selected = strconv.Itoa(g.CustomAccel)
+
+	common := widget.NewForm(
+		widget.NewFormItem("DPI", field(&dpi)),
+		widget.NewFormItem("Sensitivity", field(&g.Sens)),
+	)
+	quake := widget.NewForm(
+		widget.NewFormItem("cl_mouseAccel", field(&g.Accel)),
+		widget.NewFormItem("cl_mouseAccelOffset", field(&g.Offset)),
+		widget.NewFormItem("cl_mouseAccelPower", field(&g.Power)),
+		widget.NewFormItem("cl_mouseSensCap", field(&g.SensCap)),
+	)
+	source := widget.NewForm(
+		widget.NewFormItem("m_customaccel", customAccel),
+		widget.NewFormItem("m_customaccel_scale", field(&g.Scale)),
+		widget.NewFormItem("m_customaccel_exponent", field(&g.Exponent)),
+		widget.NewFormItem("m_customaccel_max", field(&g.Max)),
+		widget.NewFormItem("m_yaw", field(&g.Yaw)),
+		widget.NewFormItem("m_pitch", field(&g.Pitch)),
+		widget.NewFormItem("Frame rate", field(&g.FPS)),
+	)
+	game := widget.NewSelect(accelGames, func(s string) {
+		g.Game = s
+		if s == gameSource {
+			quake.Hide()
+			source.Show()
+		} else {
+			source.Hide()
+			quake.Show()
+		}
+		update()
+	})
+	game.SetSelected(gameQuakeLive)
+
+	content := container.NewBorder(container.NewVBox(game, common, quake, source, status), nil, nil, nil, chart)
+	gameDial := dialog.NewCustomConfirm("Convert game acceleration", "Open", "Cancel", content, func(ok bool) {
+		if !ok {
+			return
+		}
+		if convertErr != nil {
+			errorDialog(convertErr)
+			return
+		}
+		openProfile(converted, g.Game+" (unsaved)")
+	}, fyneApp.Window)
+	gameDial.Resize(fyne.NewSize(500, 650))
+	gameDial.Show()
+}
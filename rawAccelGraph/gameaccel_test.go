@@ -0,0 +1,89 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestQuakeLiveMultiplier(t *testing.T) {
+	g := defaultGameAccel(gameQuakeLive)
+	g.Sens, g.Accel, g.Offset, g.Power, g.SensCap = 2, 0.1, 5, 2, 3
+	tests := []struct{ speed, want float64 }{
+		{2, 1},
+		{15, (2 + 1) / 2.0},
+		// Capped at 3, 1.5 times the in-game sensitivity
+		{50, 1.5},
+	}
+	for _, test := range tests {
+		if got := g.multiplier(test.speed); !almostEqual(got, test.want) {
+			t.Errorf("speed %v = %v, want %v", test.speed, got, test.want)
+		}
+	}
+
+	g.Power, g.SensCap = 3, 0
+	if got, want := g.multiplier(25), (2+4)/2.0; !almostEqual(got, want) {
+		t.Errorf("power 3 = %v, want %v", got, want)
+	}
+}
+
+func TestSourceMultiplier(t *testing.T) {
+	g := defaultGameAccel(gameSource)
+	g.Sens, g.Scale, g.Exponent, g.FPS = 2, 0.5, 1, 500
+	// 10 counts/ms at 500 fps is 20 counts per frame
+	if got, want := g.multiplier(10), (20*0.5+2)/2; !almostEqual(got, want) {
+		t.Errorf("mode 1 = %v, want %v", got, want)
+	}
+
+	g.Max = 4
+	if got := g.multiplier(10); !almostEqual(got, 2) {
+		t.Errorf("capped = %v, want 2", got)
+	}
+
+	g.CustomAccel = 3
+	g.Exponent = 1.5
+	if got, want := g.multiplier(10), math.Sqrt(20); !almostEqual(got, want) {
+		t.Errorf("mode 3 = %v, want %v", got, want)
+	}
+
+	g.CustomAccel = 0
+	if got := g.multiplier(10); got != 1 {
+		t.Errorf("mode 0 = %v, want 1", got)
+	}
+}
+
+func TestGameAccelConfig(t *testing.T) {
+	base := Config{ConfAbcisses: "20", ConfCollumns: "4", ConfOrdonneesMin: "1", ConfOrdonneesMax: "2"}
+	g := defaultGameAccel(gameSource)
+	g.CustomAccel, g.Yaw, g.Pitch = 2, 0.022, 0.044
+	cfg, err := gameAccelConfig(base, g, 1600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.ConfDPI != "1600" || cfg.ConfRawAccel.YXRatio != 2 {
+		t.Errorf("DPI %s, Y/X ratio %v", cfg.ConfDPI, cfg.ConfRawAccel.YXRatio)
+	}
+	points := configCurve(cfg)
+	if len(points) != 5 || !almostEqual(points[4].Y, g.multiplier(points[4].X)) {
+		t.Errorf("points %v", points)
+	}
+
+	g.Game = gameQuakeLive
+	g.Power = 0.5
+	if _, err := gameAccelConfig(base, g, 800); err == nil {
+		t.Error("no error for a power below 1")
+	}
+}
+
+func TestGameAccelConfigExportsEveryPoint(t *testing.T) {
+	base := Config{ConfAbcisses: "60", ConfCollumns: "15", ConfOrdonneesMin: "1", ConfOrdonneesMax: "3"}
+	g := defaultGameAccel(gameSource)
+	g.CustomAccel = 2
+	cfg, err := gameAccelConfig(base, g, 800)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// m_customaccel 2 scales by m_yaw, far below the usual Ratio Min
+	if table := configTable(cfg); len(table) != 16 {
+		t.Errorf("%d points exported, want 16 (Ratio Min %s)", len(table), cfg.ConfOrdonneesMin)
+	}
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Steps of the speed distribution used to invert it
+const gridResolution = 1000
+
+// Explicit slider abscissas of the current profile, nil for the uniform grid
+var customGrid []float64
+
+// Slider abscissas in counts/ms: the explicit grid when there is one, else
+// columns+1 keys from 1 evenly spaced over size
+func gridKeys(columns int, size float64, grid []float64) []float64 {
+	if len(grid) > 0 {
+		return grid
+	}
+	if columns <= 0 {
+		columns = 15
+	}
+	keys := make([]float64, 0, columns+1)
+	increment := 1.0
+	for i := 0; i < columns+1; i++ {
+		keys = append(keys, increment)
+		increment = increment + size/float64(columns)
+	}
+	return keys
+}
+
+// Raw Accel rounds the abscissas, they have to stay distinct integers
+func distinctGrid(grid []float64) []float64 {
+	result := make([]float64, len(grid))
+	for i, x := range grid {
+		x = math.Max(math.Round(x), 1)
+		if i > 0 && x <= result[i-1] {
+			x = result[i-1] + 1
+		}
+		result[i] = x
+	}
+	return result
+}
+
+func scaleGrid(grid []float64, ratio float64) []float64 {
+	scaled := make([]float64, len(grid))
+	for i, x := range grid {
+		scaled[i] = x * ratio
+	}
+	return distinctGrid(scaled)
+}
+
+// Grid from 1 to max whose columns follow the time spent at each speed.
+// uniform is the share of columns kept evenly spaced so rarely reached
+// speeds still get a few points.
+func suggestGrid(events []motionEvent, columns int, max, uniform float64) ([]float64, error) {
+	if columns < 2 {
+		return nil, fmt.Errorf("at least 2 columns are needed")
+	}
+	if max <= 1 {
+		return nil, fmt.Errorf("input speed must be above 1 count/ms")
+	}
+	samples := traceSpeeds(events)
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("the trace has no movement")
+	}
+	uniform = clamp(uniform, 0, 1)
+
+	step := (max - 1) / gridResolution
+	density := make([]float64, gridResolution)
+	total := 0.0
+	for _, s := range samples {
+		i := int((math.Min(math.Max(s.Speed, 1), max) - 1) / step)
+		if i >= gridResolution {
+			i = gridResolution - 1
+		}
+		density[i] += s.Duration.Seconds()
+		total += s.Duration.Seconds()
+	}
+	// Cumulative distribution of the mix between usage and uniform spacing
+	cdf := make([]float64, gridResolution+1)
+	for i, d := range density {
+		cdf[i+1] = cdf[i] + (1-uniform)*d/total + uniform/gridResolution
+	}
+
+	grid := make([]float64, columns+1)
+	for c := range grid {
+		q := float64(c) / float64(columns) * cdf[gridResolution]
+		i := sort.SearchFloat64s(cdf, q)
+		switch {
+		case i == 0:
+			grid[c] = 1
+		case i > gridResolution:
+			grid[c] = max
+		default:
+			within := 0.0
+			if cdf[i] > cdf[i-1] {
+				within = (q - cdf[i-1]) / (cdf[i] - cdf[i-1])
+			}
+			grid[c] = 1 + (float64(i-1)+within)*step
+		}
+	}
+	grid[0], grid[columns] = 1, max
+	return distinctGrid(grid), nil
+}
+
+// Current curve moved onto grid, opened in place of the current profile
+func regridConfig(base Config, points []curvePoint, grid []float64) Config {
+	cfg := base
+	cfg.ConfGrid = grid
+	cfg.ConfCollumns = strconv.Itoa(len(grid) - 1)
+	cfg.ConfAbcisses = strconv.Itoa(int(grid[len(grid)-1] - 1))
+	regridded := make([]curvePoint, len(grid))
+	for i, x := range grid {
+		regridded[i] = curvePoint{X: x, Y: interpolate(points, x)}
+	}
+	return curveConfig(cfg, regridded)
+}
+
+func showGridDialog() {
+	if len(loadedTrace) < 2 {
+		errorDialog(fmt.Errorf("record or load a trace first in Tools > Record motion"))
+		return
+	}
+	columns := widget.NewEntry()
+	columns.Text = set.Collumns.Text
+	uniform := widget.NewSlider(0, 1)
+	uniform.Step = 0.05
+	uniform.Value = 0.25
+	grid := widget.NewLabel("")
+	grid.Wrapping = fyne.TextWrapWord
+	chart := newCurveChart()
+
+	var suggested []float64
+	update := func() {
+		suggested = nil
+		n, err := strconv.Atoi(columns.Text)
+		if err != nil {
+			grid.SetText("Invalid column count")
+			return
+		}
+		size, err := inputSpeed()
+		if err != nil {
+			grid.SetText("Invalid input speed")
+			return
+		}
+		keys, err := suggestGrid(loadedTrace, n, float64(size)+1, uniform.Value)
+		if err != nil {
+			grid.SetText(err.Error())
+			return
+		}
+		suggested = keys
+		var labels []string
+		for _, x := range keys {
+			labels = append(labels, formatSpeed(x, displayedUnit, displayedDPI))
+		}
+		grid.SetText("Columns at " + strings.Join(labels, ", ") + " " + displayedUnit)
+		points := currentCurve()
+		preview := configCurve(regridConfig(currentConfig(), points, keys))
+		chart.SetSeries(
+			chartSeries{Points: points, Color: theme.DisabledColor(), Width: 1},
+			chartSeries{Points: preview, Color: theme.PrimaryColor(), Width: 2},
+		)
+	}
+	columns.OnChanged = func(string) { update() }
+	uniform.OnChanged = func(float64) { update() }
+	update()
+
+	form := widget.NewForm(
+		widget.NewFormItem("Columns", columns),
+		widget.NewFormItem("Evenly spaced share", uniform),
+	)
+	content := container.NewBorder(container.NewVBox(form, grid), nil, nil, nil, chart)
+	gridDial := dialog.NewCustomConfirm("Suggest grid from trace", "Regrid curve", "Close", content, func(ok bool) {
+		if !ok || suggested == nil {
+			return
+		}
+		openProfile(regridConfig(currentConfig(), currentCurve(), suggested), currentProfile)
+	}, fyneApp.Window)
+	gridDial.Resize(fyne.NewSize(600, 550))
+	gridDial.Show()
+}
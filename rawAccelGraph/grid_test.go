@@ -0,0 +1,97 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// Trace moving at speed counts/ms for n polls at 1000 Hz
+func steadyTrace(events []motionEvent, speed float64, n int) []motionEvent {
+	start := time.Duration(0)
+	if len(events) > 0 {
+		start = events[len(events)-1].Time
+	}
+	for i := 1; i <= n; i++ {
+		events = append(events, motionEvent{Time: start + time.Duration(i)*time.Millisecond, DX: speed})
+	}
+	return events
+}
+
+func TestSuggestGridUniform(t *testing.T) {
+	events := steadyTrace([]motionEvent{{}}, 5, 100)
+	grid, err := suggestGrid(events, 4, 41, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []float64{1, 11, 21, 31, 41}
+	if len(grid) != len(want) {
+		t.Fatalf("got %v, want %v", grid, want)
+	}
+	for i := range want {
+		if !almostEqual(grid[i], want[i]) {
+			t.Errorf("key %d = %v, want %v", i, grid[i], want[i])
+		}
+	}
+}
+
+func TestSuggestGridFollowsUsage(t *testing.T) {
+	// Most of the time around 10 counts/ms, a short flick at 60
+	events := steadyTrace([]motionEvent{{}}, 10, 900)
+	events = steadyTrace(events, 60, 100)
+	grid, err := suggestGrid(events, 10, 80, 0.2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(grid) != 11 || grid[0] != 1 || grid[10] != 80 {
+		t.Fatalf("got %v, want 11 keys from 1 to 80", grid)
+	}
+	near := 0
+	for i, x := range grid {
+		if i > 0 && x <= grid[i-1] {
+			t.Errorf("keys not increasing: %v", grid)
+		}
+		if x != float64(int(x)) {
+			t.Errorf("key %v is not an integer", x)
+		}
+		if x >= 5 && x <= 15 {
+			near++
+		}
+	}
+	// A uniform grid would put 2 keys between 5 and 15
+	if near < 4 {
+		t.Errorf("%d keys around 10 counts/ms: %v", near, grid)
+	}
+}
+
+func TestSuggestGridErrors(t *testing.T) {
+	events := steadyTrace([]motionEvent{{}}, 5, 10)
+	tests := []struct {
+		name    string
+		events  []motionEvent
+		columns int
+		max     float64
+		msg     string
+	}{
+		{"one column", events, 1, 50, "2 columns"},
+		{"slow max", events, 10, 1, "above 1"},
+		{"no motion", []motionEvent{{}, {Time: time.Second, DX: 3}}, 10, 50, "no movement"},
+	}
+	for _, test := range tests {
+		_, err := suggestGrid(test.events, test.columns, test.max, 0.2)
+		if err == nil || !strings.Contains(err.Error(), test.msg) {
+			t.Errorf("%s: got %v, want an error with %q", test.name, err, test.msg)
+		}
+	}
+}
+
+func TestDistinctGrid(t *testing.T) {
+	got := distinctGrid([]float64{0.2, 1.4, 1.6, 2.1, 7.5})
+	want := []float64{1, 2, 3, 4, 8}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got %v, want %v", got, want)
+			break
+		}
+	}
+}
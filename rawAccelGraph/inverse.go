@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Steps per segment used to find where the output speed goes down
+const inverseSteps = 50
+
+// Output speed in counts/ms for an input speed
+func outputSpeed(points []curvePoint, v float64) float64 {
+	return v * interpolate(points, v)
+}
+
+// Input speeds where the output speed stops increasing, as [from, to] pairs
+func nonIncreasingRanges(points []curvePoint) [][2]float64 {
+	var ranges [][2]float64
+	last := points[len(points)-1].X
+	step := last / float64(inverseSteps*len(points))
+	if step <= 0 {
+		return nil
+	}
+	inRange := false
+	for v := 0.0; v < last; v += step {
+		next := math.Min(v+step, last)
+		if outputSpeed(points, next) <= outputSpeed(points, v) {
+			if !inRange {
+				ranges = append(ranges, [2]float64{v, next})
+				inRange = true
+			}
+			ranges[len(ranges)-1][1] = next
+		} else {
+			inRange = false
+		}
+	}
+	return ranges
+}
+
+// Smallest input speed giving the output speed u
+func inverseSpeed(points []curvePoint, u float64) float64 {
+	breaks := []float64{0}
+	for _, p := range points {
+		breaks = append(breaks, p.X)
+	}
+	for i := 1; i < len(breaks); i++ {
+		a, b := breaks[i-1], breaks[i]
+		if outputSpeed(points, b) < u {
+			continue
+		}
+		// The output speed is continuous, bisect inside the segment
+		for j := 0; j < 60; j++ {
+			mid := (a + b) / 2
+			if outputSpeed(points, mid) < u {
+				a = mid
+			} else {
+				b = mid
+			}
+		}
+		return (a + b) / 2
+	}
+	// Constant sensitivity after the last point
+	return u / points[len(points)-1].Y
+}
+
+// Sensitivity table undoing points in output speed space: feeding the output
+// of points into the inverse gives back the input speed. keys are output
+// speeds in counts/ms.
+func inverseCurve(points []curvePoint, keys []float64) ([]curvePoint, []string, error) {
+	if len(points) == 0 {
+		return nil, nil, fmt.Errorf("the curve is empty")
+	}
+	for _, p := range points {
+		if p.Y <= 0 {
+			return nil, nil, fmt.Errorf("sensitivity at %s is not positive, the curve can't be inverted",
+				formatSpeed(p.X, displayedUnit, displayedDPI))
+		}
+	}
+	var warnings []string
+	for _, r := range nonIncreasingRanges(points) {
+		warnings = append(warnings, fmt.Sprintf("Output speed does not increase from %s to %s, the inverse uses the slowest matching speed",
+			formatSpeed(r[0], displayedUnit, displayedDPI), formatSpeed(r[1], displayedUnit, displayedDPI)))
+	}
+
+	inverse := make([]curvePoint, len(keys))
+	for i, u := range keys {
+		v := inverseSpeed(points, u)
+		inverse[i] = curvePoint{X: u, Y: 1 / interpolate(points, v)}
+	}
+	return inverse, warnings, nil
+}
+
+// Largest relative error of the curve followed by its inverse on the grid of points
+func inverseError(points, inverse []curvePoint) float64 {
+	worst := 0.0
+	for _, p := range combineCurves(arithmeticCompose, points, inverse) {
+		worst = math.Max(worst, math.Abs(p.Y-1))
+	}
+	return worst
+}
+
+// Profile with the inverse of base, its uniform grid spans the output
+// speeds of base up to the last point
+func inverseConfig(base Config) (Config, []string, error) {
+	points := configCurve(base)
+	if len(points) == 0 {
+		return Config{}, nil, fmt.Errorf("the curve is empty")
+	}
+	cfg := base
+	cfg.ConfGrid = nil
+	maxOutput := outputSpeed(points, points[len(points)-1].X)
+	cfg.ConfAbcisses = strconv.Itoa(int(math.Max(math.Ceil(maxOutput-1), 1)))
+	grid := configCurve(cfg)
+	keys := make([]float64, len(grid))
+	for i, p := range grid {
+		keys[i] = p.X
+	}
+	inverse, warnings, err := inverseCurve(points, keys)
+	if err != nil {
+		return Config{}, nil, err
+	}
+	if cfg.ConfRawAccel.SeparateXY {
+		warnings = append(warnings, "Only the X curve is inverted, the Y curve is dropped")
+		cfg.ConfRawAccel.SeparateXY = false
+	}
+	cfg.ConfGraphY = nil
+	cfg.ConfYRatio = 0
+	return curveConfig(cfg, inverse), warnings, nil
+}
+
+func showInverseDialog() {
+	points := currentCurve()
+	if len(points) == 0 {
+		return
+	}
+	cfg, warnings, err := inverseConfig(currentConfig())
+	if err != nil {
+		errorDialog(err)
+		return
+	}
+	inverse := configCurve(cfg)
+	warnings = append(warnings, fmt.Sprintf("Curve followed by its inverse is within %s%% of 1:1 on the grid",
+		formatFloat(inverseError(points, inverse)*100, 2)))
+	status := widget.NewLabel(strings.Join(warnings, "\n"))
+	status.Wrapping = fyne.TextWrapWord
+
+	chart := newCurveChart()
+	chart.SetSeries(
+		chartSeries{Points: points, Color: theme.DisabledColor(), Width: 1},
+		chartSeries{Points: inverse, Color: theme.PrimaryColor(), Width: 2},
+	)
+	content := container.NewBorder(nil, status, nil, nil, chart)
+	inverseDial := dialog.NewCustomConfirm("Inverse curve", "Open", "Cancel", content, func(ok bool) {
+		if ok {
+			openProfile(cfg, strings.TrimSuffix(profileName(), " (unsaved)")+" inverse (unsaved)")
+		}
+	}, fyneApp.Window)
+	inverseDial.Resize(fyne.NewSize(550, 500))
+	inverseDial.Show()
+}
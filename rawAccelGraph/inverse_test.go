@@ -0,0 +1,96 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestInverseConstantCurve(t *testing.T) {
+	points := []curvePoint{{X: 1, Y: 2}, {X: 50, Y: 2}}
+	inverse, warnings, err := inverseCurve(points, []float64{1, 10, 100, 200})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) > 0 {
+		t.Errorf("unexpected warnings %v", warnings)
+	}
+	for _, p := range inverse {
+		if !almostEqual(p.Y, 0.5) {
+			t.Errorf("inverse at %v = %v, want 0.5", p.X, p.Y)
+		}
+	}
+}
+
+func TestInverseUndoesCurve(t *testing.T) {
+	var points []curvePoint
+	var keys []float64
+	for x := 1.0; x <= 41; x += 4 {
+		points = append(points, curvePoint{X: x, Y: 1 + 0.05*x})
+	}
+	for u := 1.0; u <= 200; u++ {
+		keys = append(keys, u)
+	}
+	inverse, warnings, err := inverseCurve(points, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) > 0 {
+		t.Errorf("unexpected warnings %v", warnings)
+	}
+	for _, v := range []float64{3, 12.5, 30} {
+		u := outputSpeed(points, v)
+		if back := u * interpolate(inverse, u); math.Abs(back-v) > 0.01 {
+			t.Errorf("speed %v comes back as %v", v, back)
+		}
+	}
+	if e := inverseError(points, inverse); e > 0.01 {
+		t.Errorf("composition error %v", e)
+	}
+}
+
+func TestInverseWarnings(t *testing.T) {
+	// Output speed 20 at 10 counts/ms, 12 at 20 counts/ms
+	points := []curvePoint{{X: 10, Y: 2}, {X: 20, Y: 0.6}}
+	_, warnings, err := inverseCurve(points, []float64{5, 15})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 1 {
+		t.Errorf("got warnings %v, want one range", warnings)
+	}
+
+	if _, _, err := inverseCurve([]curvePoint{{X: 1, Y: 0}}, []float64{1}); err == nil {
+		t.Error("no error for a zero sensitivity")
+	}
+}
+
+func TestInverseConfig(t *testing.T) {
+	base := Config{ConfAbcisses: "40", ConfCollumns: "20", ConfOrdonneesMin: "0", ConfOrdonneesMax: "3", ConfDPI: "800"}
+	points := configCurve(base)
+	for i := range points {
+		points[i].Y = 1 + 0.05*points[i].X
+	}
+	base = curveConfig(base, points)
+
+	cfg, warnings, err := inverseConfig(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) > 0 {
+		t.Errorf("unexpected warnings %v", warnings)
+	}
+	inverse := configCurve(cfg)
+	// The grid of the inverse is in output speed: 41 counts/ms leave at 41 * 3.05
+	if last := inverse[len(inverse)-1].X; last < outputSpeed(points, 41) {
+		t.Errorf("inverse grid ends at %v, before the output speed %v", last, outputSpeed(points, 41))
+	}
+	for _, v := range []float64{3, 12.5, 30, 41} {
+		u := outputSpeed(points, v)
+		if back := u * interpolate(inverse, u); math.Abs(back-v)/v > 0.02 {
+			t.Errorf("speed %v comes back as %v", v, back)
+		}
+	}
+	if e := inverseError(points, inverse); e > 0.02 {
+		t.Errorf("composition error %v", e)
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	libinputMotion   = "motion"
+	libinputFallback = "fallback"
+	libinputScroll   = "scroll"
+
+	libinputXinput   = "xinput"
+	libinputHyprland = "hyprland"
+	libinputSway     = "sway"
+
+	// libinput accepts at most 64 points per custom function
+	libinputMaxPoints = 64
+	// Speeds are normalized to a 1000 DPI device
+	libinputDPI = 1000
+)
+
+var libinputMotions = []string{libinputMotion, libinputFallback, libinputScroll}
+var libinputFormats = []string{libinputXinput, libinputHyprland, libinputSway}
+
+// Custom acceleration function: Points[i] is the output speed for an input
+// speed of i*Step, both in normalized units/ms
+type libinputProfile struct {
+	Motion string
+	Step   float64
+	Points []float64
+}
+
+// Resample the curve on libinput's fixed step. A step of 0 spreads count
+// points over the whole curve.
+func resampleLibinput(points []curvePoint, dpi, step float64, count int, motion string) (libinputProfile, error) {
+	if len(points) == 0 {
+		return libinputProfile{}, fmt.Errorf("empty curve")
+	}
+	if dpi <= 0 {
+		return libinputProfile{}, fmt.Errorf("DPI must be positive")
+	}
+	if count < 2 || count > libinputMaxPoints {
+		return libinputProfile{}, fmt.Errorf("libinput needs between 2 and %d points", libinputMaxPoints)
+	}
+	toNormalized := libinputDPI / dpi
+	if step <= 0 {
+		step = points[len(points)-1].X * toNormalized / float64(count-1)
+	}
+	profile := libinputProfile{Motion: motion, Step: step}
+	for i := 0; i < count; i++ {
+		speed := float64(i) * step
+		counts := speed / toNormalized
+		profile.Points = append(profile.Points, speed*interpolate(points, counts))
+	}
+	return profile, nil
+}
+
+func (p libinputProfile) joinPoints(sep string) string {
+	values := make([]string, len(p.Points))
+	for i, v := range p.Points {
+		values[i] = formatFloat(v, 3)
+	}
+	return strings.Join(values, sep)
+}
+
+func (p libinputProfile) xinputScript(device string) string {
+	motion := strings.ToUpper(p.Motion[:1]) + p.Motion[1:]
+	var sb strings.Builder
+	sb.WriteString("#!/bin/sh\n")
+	fmt.Fprintf(&sb, "DEVICE=\"${1:-%s}\"\n", device)
+	sb.WriteString("xinput set-prop \"$DEVICE\" \"libinput Accel Profile Enabled\" 0, 0, 1\n")
+	fmt.Fprintf(&sb, "xinput set-prop \"$DEVICE\" \"libinput Accel Custom %s Points\" %s\n", motion, p.joinPoints(", "))
+	fmt.Fprintf(&sb, "xinput set-prop \"$DEVICE\" \"libinput Accel Custom %s Step\" %s\n", motion, formatFloat(p.Step, 3))
+	return sb.String()
+}
+
+func (p libinputProfile) hyprland() string {
+	return fmt.Sprintf("input {\n    accel_profile = custom %s %s\n}\n", formatFloat(p.Step, 3), p.joinPoints(" "))
+}
+
+// Same layout as sway input blocks, for builds with custom profile support
+func (p libinputProfile) sway(device string) string {
+	if device == "" {
+		device = "type:pointer"
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "input %s {\n", device)
+	sb.WriteString("    accel_profile custom\n")
+	fmt.Fprintf(&sb, "    custom_accel_%s_step %s\n", p.Motion, formatFloat(p.Step, 3))
+	fmt.Fprintf(&sb, "    custom_accel_%s_points %s\n", p.Motion, p.joinPoints(" "))
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+func (p libinputProfile) export(format, device string) (string, error) {
+	switch format {
+	case libinputXinput:
+		if device == "" {
+			device = "pointer:Mouse"
+		}
+		return p.xinputScript(device), nil
+	case libinputHyprland:
+		return p.hyprland(), nil
+	case libinputSway:
+		return p.sway(device), nil
+	}
+	return "", fmt.Errorf("unknown libinput format %q", format)
+}
+
+func showLibinputDialog() {
+	motion := widget.NewSelect(libinputMotions, nil)
+	motion.SetSelected(libinputMotion)
+	format := widget.NewSelect(libinputFormats, nil)
+	format.SetSelected(libinputXinput)
+	step := widget.NewEntry()
+	step.Text = "0"
+	count := widget.NewEntry()
+	count.Text = strconv.Itoa(libinputMaxPoints)
+	device := widget.NewEntry()
+	device.PlaceHolder = "device name"
+	output := widget.NewMultiLineEntry()
+	output.Wrapping = fyne.TextWrapWord
+
+	update := func() {
+		s, err := strconv.ParseFloat(step.Text, 64)
+		if err != nil {
+			output.SetText("Invalid step")
+			return
+		}
+		n, err := strconv.Atoi(count.Text)
+		if err != nil {
+			output.SetText("Invalid point count")
+			return
+		}
+		profile, err := resampleLibinput(currentCurve(), settingsDPI(), s, n, motion.Selected)
+		if err != nil {
+			output.SetText(err.Error())
+			return
+		}
+		text, err := profile.export(format.Selected, device.Text)
+		if err != nil {
+			output.SetText(err.Error())
+			return
+		}
+		output.SetText(text)
+	}
+	motion.OnChanged = func(string) { update() }
+	format.OnChanged = func(string) { update() }
+	step.OnChanged = func(string) { update() }
+	count.OnChanged = func(string) { update() }
+	device.OnChanged = func(string) { update() }
+	update()
+
+	form := widget.NewForm(
+		widget.NewFormItem("Motion type", motion),
+		widget.NewFormItem("Output", format),
+		widget.NewFormItem("Step (0 = auto)", step),
+		widget.NewFormItem("Points", count),
+		widget.NewFormItem("Device", device),
+	)
+	copyBtn := widget.NewButton("Copy", func() {
+		fyneApp.Window.Clipboard().SetContent(output.Text)
+	})
+	scroll := container.NewVScroll(output)
+	scroll.SetMinSize(fyne.NewSize(450, 200))
+	libinputDial := dialog.NewCustom("libinput custom profile", "Close", container.NewVBox(form, scroll, copyBtn), fyneApp.Window)
+	libinputDial.Resize(fyne.NewSize(550, 550))
+	libinputDial.Show()
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestResampleLibinputFlatCurve(t *testing.T) {
+	points := []curvePoint{{X: 1, Y: 1}, {X: 100, Y: 1}}
+	profile, err := resampleLibinput(points, 1000, 2, 5, libinputMotion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []float64{0, 2, 4, 6, 8}
+	for i, v := range want {
+		if !almostEqual(profile.Points[i], v) {
+			t.Errorf("point %d = %v, want %v", i, profile.Points[i], v)
+		}
+	}
+}
+
+func TestResampleLibinputNormalizesDPI(t *testing.T) {
+	// Sensitivity goes from 1 at 1 count/ms to 2 at 11 counts/ms
+	points := []curvePoint{{X: 1, Y: 1}, {X: 11, Y: 2}}
+	profile, err := resampleLibinput(points, 500, 3, 4, libinputMotion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 3 units/ms at 1000 DPI is 1.5 counts/ms at 500 DPI: 3 * 1.05
+	// 9 units/ms is 4.5 counts/ms: 9 * 1.35
+	want := []float64{0, 3.15, 6 * 1.2, 12.15}
+	for i, v := range want {
+		if !almostEqual(profile.Points[i], v) {
+			t.Errorf("point %d = %v, want %v", i, profile.Points[i], v)
+		}
+	}
+}
+
+func TestResampleLibinputAutoStep(t *testing.T) {
+	points := []curvePoint{{X: 1, Y: 1}, {X: 64, Y: 1.5}}
+	profile, err := resampleLibinput(points, 800, 0, 64, libinputMotion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !almostEqual(profile.Step, 64*1.25/63) {
+		t.Errorf("step = %v, want %v", profile.Step, 64*1.25/63)
+	}
+	// The last point falls on the end of the curve
+	if !almostEqual(profile.Points[63], 64*1.25*1.5) {
+		t.Errorf("last point = %v, want %v", profile.Points[63], 64*1.25*1.5)
+	}
+}
+
+func TestResampleLibinputLimits(t *testing.T) {
+	points := []curvePoint{{X: 1, Y: 1}, {X: 10, Y: 1}}
+	if _, err := resampleLibinput(points, 800, 1, libinputMaxPoints+1, libinputMotion); err == nil {
+		t.Error("expected an error above the libinput point limit")
+	}
+	if _, err := resampleLibinput(points, 0, 1, 10, libinputMotion); err == nil {
+		t.Error("expected an error for a null DPI")
+	}
+}
+
+func TestLibinputExport(t *testing.T) {
+	profile := libinputProfile{Motion: libinputMotion, Step: 1, Points: []float64{0, 1, 2.5}}
+	text, err := profile.export(libinputXinput, "pointer:Test Mouse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text, `"libinput Accel Custom Motion Points" 0.000, 1.000, 2.500`) {
+		t.Errorf("unexpected xinput script:\n%s", text)
+	}
+	text, _ = profile.export(libinputHyprland, "")
+	if !strings.Contains(text, "accel_profile = custom 1.000 0.000 1.000 2.500") {
+		t.Errorf("unexpected hyprland config:\n%s", text)
+	}
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	driverLeetmouse = "leetmouse"
+	driverMaccel    = "maccel"
+
+	maccelRoot = "/sys/module/maccel/parameters"
+	// maccel reads its parameters as 32.32 fixed point integers
+	maccelFracBits = 32
+	// and normalizes speeds to a 1000 DPI device
+	maccelDPI = 1000
+)
+
+var linuxDrivers = []string{driverLeetmouse, driverMaccel}
+
+// leetmouse adds the accelerated part to the sensitivity instead of scaling it
+var leetmouseMode = accelMode{
+	Name:    "leetmouse",
+	Params:  []string{"ACCELERATION", "OFFSET", "EXPONENT", "SENSITIVITY"},
+	Initial: []float64{0.01, 0, 2, 1},
+	Abs:     []int{0, 1},
+	Min:     map[int]float64{2: 1},
+	Sens: func(x float64, p []float64) float64 {
+		return p[3] + math.Pow(math.Abs(p[0])*math.Max(x-math.Abs(p[1]), 0), math.Max(p[2], 1)-1)
+	},
+}
+
+// Parameter names are the maccel sysfs files, the index is the MODE value
+var maccelModes = []accelMode{
+	{
+		Name:    "linear",
+		Params:  []string{"ACCEL", "OFFSET", "SENS_MULT"},
+		Initial: []float64{0.01, 0, 1},
+		Abs:     []int{0},
+		Sens: func(x float64, p []float64) float64 {
+			return p[2] * (1 + math.Abs(p[0])*math.Max(x-p[1], 0))
+		},
+	},
+	{
+		Name:    "natural",
+		Params:  []string{"DECAY_RATE", "OFFSET", "LIMIT", "SENS_MULT"},
+		Initial: []float64{0.1, 0, 2, 1},
+		Abs:     []int{0},
+		Sens:    accelModes[1].Sens,
+	},
+}
+
+type driverParam struct {
+	Name  string
+	Value float64
+	// Written as is instead of fixed point
+	Integer bool
+}
+
+// Curve approximated by the parameters of a kernel driver
+type driverParams struct {
+	Driver string
+	Fit    fitResult
+	Params []driverParam
+}
+
+func fitLeetmouse(points []curvePoint) driverParams {
+	fit := fitMode(leetmouseMode, points)
+	d := driverParams{Driver: driverLeetmouse, Fit: fit}
+	for i, name := range leetmouseMode.Params {
+		d.Params = append(d.Params, driverParam{Name: name, Value: fit.Params[i]})
+	}
+	d.Params = append(d.Params,
+		driverParam{Name: "SENS_CAP", Value: maxSens(points)},
+		driverParam{Name: "SPEED_CAP"},
+		driverParam{Name: "POST_SCALE_X", Value: 1},
+		driverParam{Name: "POST_SCALE_Y", Value: 1},
+	)
+	return d
+}
+
+func fitMaccel(points []curvePoint, dpi float64) driverParams {
+	normalized := make([]curvePoint, len(points))
+	for i, p := range points {
+		normalized[i] = curvePoint{X: p.X * maccelDPI / dpi, Y: p.Y}
+	}
+	var d driverParams
+	for mode, accel := range maccelModes {
+		fit := fitMode(accel, normalized)
+		if d.Driver != "" && fit.RMS >= d.Fit.RMS {
+			continue
+		}
+		d = driverParams{Driver: driverMaccel, Fit: fit}
+		d.Params = append(d.Params, driverParam{"MODE", float64(mode), true}, driverParam{Name: "INPUT_DPI", Value: dpi})
+		for i, name := range accel.Params {
+			d.Params = append(d.Params, driverParam{Name: name, Value: fit.Params[i]})
+		}
+		if accel.Name == "linear" {
+			d.Params = append(d.Params, driverParam{Name: "OUTPUT_CAP", Value: maxSens(points) / fit.Params[2]})
+		}
+	}
+	return d
+}
+
+func fitDriver(driver string, points []curvePoint, dpi float64) (driverParams, error) {
+	if len(points) < 2 {
+		return driverParams{}, fmt.Errorf("at least two points are needed")
+	}
+	switch driver {
+	case driverLeetmouse:
+		return fitLeetmouse(points), nil
+	case driverMaccel:
+		if dpi <= 0 {
+			return driverParams{}, fmt.Errorf("DPI must be positive")
+		}
+		return fitMaccel(points, dpi), nil
+	}
+	return driverParams{}, fmt.Errorf("unknown driver %q", driver)
+}
+
+func maxSens(points []curvePoint) float64 {
+	max := 0.0
+	for _, p := range points {
+		max = math.Max(max, p.Y)
+	}
+	return max
+}
+
+func (p driverParam) sysfsValue() string {
+	if p.Integer {
+		return strconv.Itoa(int(p.Value))
+	}
+	return maccelFixed(p.Value)
+}
+
+func maccelFixed(v float64) string {
+	return strconv.FormatInt(int64(math.Round(v*(1<<maccelFracBits))), 10)
+}
+
+// Replacement for leetmouse's config.h, the driver has to be rebuilt
+func (d driverParams) header() string {
+	var sb strings.Builder
+	sb.WriteString("// Generated by rawAccelGraph, approximation of the curve\n")
+	fmt.Fprintf(&sb, "// RMS error: %s\n\n", formatFloat(d.Fit.RMS, 4))
+	for _, p := range d.Params {
+		fmt.Fprintf(&sb, "#define %s %s\n", p.Name, strconv.FormatFloat(p.Value, 'f', -1, 64))
+	}
+	return sb.String()
+}
+
+func (d driverParams) maccelScript(root string) string {
+	var sb strings.Builder
+	sb.WriteString("#!/bin/sh\n")
+	fmt.Fprintf(&sb, "# Generated by rawAccelGraph, %s mode, RMS error: %s\n", d.Fit.Mode.Name, formatFloat(d.Fit.RMS, 4))
+	fmt.Fprintf(&sb, "ROOT=\"${1:-%s}\"\n", root)
+	for _, p := range d.Params {
+		fmt.Fprintf(&sb, "echo %s > \"$ROOT/%s\"\n", p.sysfsValue(), p.Name)
+	}
+	return sb.String()
+}
+
+func (d driverParams) export(root string) string {
+	if d.Driver == driverLeetmouse {
+		return d.header()
+	}
+	return d.maccelScript(root)
+}
+
+// Write the parameters to the maccel sysfs directory, root is only changed
+// to write somewhere else than the loaded module
+func writeMaccelParams(root string, d driverParams) error {
+	if d.Driver != driverMaccel {
+		return fmt.Errorf("only maccel parameters can be written")
+	}
+	if info, err := os.Stat(root); err != nil {
+		return err
+	} else if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", root)
+	}
+	for _, p := range d.Params {
+		if err := ioutil.WriteFile(filepath.Join(root, p.Name), []byte(p.sysfsValue()), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func showLinuxDriverDialog() {
+	driver := widget.NewSelect(linuxDrivers, nil)
+	root := widget.NewEntry()
+	root.Text = maccelRoot
+	output := widget.NewMultiLineEntry()
+	output.Wrapping = fyne.TextWrapWord
+
+	var params driverParams
+	update := func() {
+		var err error
+		params, err = fitDriver(driver.Selected, currentCurve(), settingsDPI())
+		if err != nil {
+			output.SetText(err.Error())
+			return
+		}
+		output.SetText(params.export(root.Text))
+	}
+	driver.OnChanged = func(string) { update() }
+	root.OnChanged = func(string) {
+		if driver.Selected == driverMaccel {
+			update()
+		}
+	}
+	driver.SetSelected(driverMaccel)
+
+	copyBtn := widget.NewButton("Copy", func() {
+		fyneApp.Window.Clipboard().SetContent(output.Text)
+	})
+	saveBtn := widget.NewButton("Save...", func() {
+		dialog.ShowFileSave(func(writer fyne.URIWriteCloser, err error) {
+			if err != nil || writer == nil {
+				errorDialog(err)
+				return
+			}
+			defer writer.Close()
+			_, err = io.WriteString(writer, output.Text)
+			errorDialog(err)
+		}, fyneApp.Window)
+	})
+	applyBtn := widget.NewButton("Write to sysfs", func() {
+		if err := writeMaccelParams(root.Text, params); err != nil {
+			errorDialog(err)
+			return
+		}
+		dialog.ShowInformation("maccel", "Parameters written to "+root.Text, fyneApp.Window)
+	})
+
+	form := widget.NewForm(
+		widget.NewFormItem("Driver", driver),
+		widget.NewFormItem("maccel parameters", root),
+	)
+	scroll := container.NewVScroll(output)
+	scroll.SetMinSize(fyne.NewSize(450, 250))
+	driverDial := dialog.NewCustom("Linux driver parameters", "Close",
+		container.NewVBox(form, scroll, container.NewHBox(copyBtn, saveBtn, applyBtn)), fyneApp.Window)
+	driverDial.Resize(fyne.NewSize(550, 550))
+	driverDial.Show()
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func linearCurve() []curvePoint {
+	var points []curvePoint
+	for x := 1.0; x <= 40; x++ {
+		points = append(points, curvePoint{X: x, Y: 1 + 0.05*x})
+	}
+	return points
+}
+
+func TestFitMaccelLinear(t *testing.T) {
+	params, err := fitDriver(driverMaccel, linearCurve(), maccelDPI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params.Fit.Mode.Name != "linear" {
+		t.Errorf("mode = %s, want linear", params.Fit.Mode.Name)
+	}
+	if params.Fit.RMS > 1e-3 {
+		t.Errorf("RMS = %v, want a near exact fit", params.Fit.RMS)
+	}
+}
+
+func TestWriteMaccelParams(t *testing.T) {
+	root, err := ioutil.TempDir("", "maccel")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	params, err := fitDriver(driverMaccel, linearCurve(), 800)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeMaccelParams(root, params); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range params.Params {
+		data, err := ioutil.ReadFile(filepath.Join(root, p.Name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != p.sysfsValue() {
+			t.Errorf("%s = %s, want %s", p.Name, data, p.sysfsValue())
+		}
+	}
+	mode, _ := ioutil.ReadFile(filepath.Join(root, "MODE"))
+	if string(mode) != "0" {
+		t.Errorf("MODE = %s, want 0 for linear", mode)
+	}
+	dpi, _ := ioutil.ReadFile(filepath.Join(root, "INPUT_DPI"))
+	if string(dpi) != "3435973836800" {
+		t.Errorf("INPUT_DPI = %s, want 800 in 32.32 fixed point", dpi)
+	}
+}
+
+func TestWriteMaccelParamsMissingRoot(t *testing.T) {
+	params, _ := fitDriver(driverMaccel, linearCurve(), 800)
+	if err := writeMaccelParams(filepath.Join(os.TempDir(), "no-maccel-here"), params); err == nil {
+		t.Error("expected an error when maccel is not loaded")
+	}
+	leet, _ := fitDriver(driverLeetmouse, linearCurve(), 800)
+	if err := writeMaccelParams(os.TempDir(), leet); err == nil {
+		t.Error("expected an error for leetmouse parameters")
+	}
+}
+
+func TestLeetmouseHeader(t *testing.T) {
+	params, err := fitDriver(driverLeetmouse, linearCurve(), 800)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := params.header()
+	for _, name := range []string{"SENSITIVITY", "ACCELERATION", "EXPONENT", "SENS_CAP"} {
+		if !strings.Contains(header, "#define "+name+" ") {
+			t.Errorf("header misses %s:\n%s", name, header)
+		}
+	}
+	// The buffer size belongs to the driver, not to the curve
+	if strings.Contains(header, "BUFFER_SIZE") {
+		t.Errorf("header overrides BUFFER_SIZE:\n%s", header)
+	}
+}
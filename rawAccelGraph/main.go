@@ -11,7 +11,6 @@ import (
 
 	_ "embed"
 	"path/filepath"
-	"text/template"
 
 	"fyne.io/fyne/v2/dialog"
 
@@ -27,12 +26,18 @@ import (
 )
 
 type Graph struct {
-	LeftContainer  *fyne.Container
-	RightContainer fyne.Container
-	RightBottom    fyne.Container
-	LabelSlider    map[float64]*canvas.Text
-	Sliders        map[float64]*widget.Slider
-	SliderAbs      map[float64]*canvas.Text
+	LeftContainer   *fyne.Container
+	RightContainer  fyne.Container
+	RightBottom     fyne.Container
+	LabelSlider     map[float64]*canvas.Text
+	Sliders         map[float64]*widget.Slider
+	SliderAbs       map[float64]*canvas.Text
+	Histogram       map[float64]*fyne.Container
+	RightContainerY fyne.Container
+	SlidersY        map[float64]*widget.Slider
+	CurveArea       *fyne.Container
+	Chart           *curveChart
+	Overlays        []chartSeries
 }
 
 type Settings struct {
@@ -40,7 +45,12 @@ type Settings struct {
 	Abcisses     *widget.Entry
 	OrdonneesMax *widget.Entry
 	OrdonneesMin *widget.Entry
+	DPI          *widget.Entry
+	PollingRate  *widget.Entry
+	Unit         *widget.Select
+	Template     *widget.Select
 	Result       *widget.Entry
+	Violations   *widget.Label
 }
 
 type RawAccelData struct {
@@ -57,7 +67,17 @@ type Config struct {
 	ConfOrdonneesMin string
 	ConfCollumns     string
 	ConfResult       string
+	ConfDPI          string
+	ConfPollingRate  string
+	ConfUnit         string
 	ConfGraph        map[float64]float64
+	ConfConstraints  Constraints
+	ConfGrid         []float64 `yaml:",omitempty"`
+	ConfRawAccel     RawAccelSettings
+	ConfGraphY       map[float64]float64 `yaml:",omitempty"`
+	ConfYRatio       float64             `yaml:",omitempty"`
+	ConfExpression   string              `yaml:",omitempty"`
+	ConfExprUnit     string              `yaml:",omitempty"`
 }
 
 type FyneApp struct {
@@ -70,6 +90,7 @@ var set Settings
 var rawAccel RawAccelData
 var importConf Config
 var fyneApp FyneApp
+var currentProfile string
 
 //go:embed rawAccell.tmpl
 var b []byte
@@ -81,9 +102,13 @@ func init() {
 }
 
 func main() {
+	if len(os.Args) > 1 {
+		os.Exit(runCLI(os.Args[1:]))
+	}
+
 	//Global App et Window setting
 	fyneApp.App = app.New()
-	fyneApp.Window = fyneApp.App.NewWindow("Raw Accel Data generator by Nicolas HYPOLITE")
+	fyneApp.Window = fyneApp.App.NewWindow(windowTitle())
 	fyneApp.App.Settings().SetTheme(theme.DarkTheme())
 	fyneApp.Window.SetIcon(resourceIconPng)
 
@@ -95,6 +120,7 @@ func main() {
 	ui.Sliders = make(map[float64]*widget.Slider)
 	ui.LabelSlider = make(map[float64]*canvas.Text)
 	ui.SliderAbs = make(map[float64]*canvas.Text)
+	ui.Histogram = make(map[float64]*fyne.Container)
 
 	ui.LeftContainer = container.NewVBox(settings(), &widget.Separator{}, genUIConfig(), result())
 	genGraph(false)
@@ -102,7 +128,20 @@ func main() {
 	// Load Default Config
 	loadConfig("current.yml")
 
-	result := container.NewHSplit(ui.LeftContainer, &ui.RightContainer)
+	ui.Chart = newCurveChart()
+	ui.Chart.OnSelected = setSelection
+	refreshChart()
+	ui.CurveArea = container.NewMax()
+	layoutCurveArea()
+	right := container.NewVSplit(container.NewBorder(yAxisBar(), nil, nil, nil, ui.CurveArea), ui.Chart)
+	right.Offset = 0.75
+
+	tabs := container.NewAppTabs(
+		container.NewTabItem("Curve", right),
+		container.NewTabItem("Raw Accel settings", rawAccelSettingsTab()),
+	)
+
+	result := container.NewHSplit(ui.LeftContainer, tabs)
 	result.Offset = 0.1
 
 	fyneApp.Window.Resize(fyne.NewSize(1000, 600))
@@ -126,14 +165,34 @@ func settings() *fyne.Container {
 	set.OrdonneesMin = widget.NewEntry()
 	set.OrdonneesMin.Text = "0.17"
 
+	set.DPI = widget.NewEntry()
+	set.DPI.Text = strconv.Itoa(defaultDPI)
+	set.DPI.OnChanged = func(s string) {
+		if dpi, err := strconv.ParseFloat(s, 64); err == nil && dpi > 0 {
+			changeSpeedUnit(displayedUnit, dpi)
+		}
+	}
+
+	set.PollingRate = widget.NewEntry()
+	set.PollingRate.Text = strconv.Itoa(defaultPollingRate)
+
+	set.Unit = widget.NewSelect(speedUnits, func(s string) {
+		changeSpeedUnit(s, settingsDPI())
+	})
+	set.Unit.Selected = unitCountsMs
+
 	form := &widget.Form{
 		Items: []*widget.FormItem{
 			{Text: "Precize", Widget: set.Collumns},
 			{Text: "Input Speed", Widget: set.Abcisses},
+			{Text: "Speed Unit", Widget: set.Unit},
+			{Text: "DPI", Widget: set.DPI},
+			{Text: "Polling Hz", Widget: set.PollingRate},
 			{Text: "Ratio Min", Widget: set.OrdonneesMin},
 			{Text: "Ratio Max", Widget: set.OrdonneesMax}},
 		OnSubmit: func() {
 			rawAccel.Data = map[int]string{}
+			customGrid = nil
 			genGraph(false)
 			ui.RightContainer.Refresh()
 		},
@@ -150,11 +209,17 @@ func result() *fyne.Container {
 	scroll := container.NewVScroll(set.Result)
 	scroll.SetMinSize(fyne.Size{Height: 300})
 
+	set.Template = widget.NewSelect(listTemplates(), func(string) {
+		genAccelRaw()
+	})
+	set.Template.Selected = builtinTemplate
+
 	bottomBox := container.NewHBox(
+		set.Template,
 		&widget.Separator{},
-		widget.NewButtonWithIcon("   Copy   ", theme.ContentCopyIcon(), func() {
+		widget.NewButtonWithIcon("   Copy   ", theme.ContentCopyIcon(), checkedExport("Copy", func() {
 			fyneApp.Window.Clipboard().SetContent(set.Result.Text)
-		}),
+		})),
 	)
 
 	aCoffe := container.NewHBox(
@@ -164,34 +229,47 @@ func result() *fyne.Container {
 		}),
 	)
 
-	result := container.NewVBox(scroll, container.NewCenter(bottomBox), container.NewCenter(aCoffe))
+	set.Violations = widget.NewLabel("")
+	set.Violations.Wrapping = fyne.TextWrapWord
+	violations := container.NewVScroll(set.Violations)
+	violations.SetMinSize(fyne.Size{Height: 60})
+
+	result := container.NewVBox(scroll, violations, container.NewCenter(bottomBox), container.NewCenter(aCoffe))
 
 	return result
 }
 
+// Sensitivity step of the sliders for the ordinate maximum max
+func sliderStep(max string) float64 {
+	value, _ := strconv.ParseFloat(max, 64)
+	return value / 2160
+}
+
 func genGraph(loadFromSave bool) {
-	var collumnsInc float64
 	var nbcollumns int
 
 	nbcollumns, _ = strconv.Atoi(set.Collumns.Text)
 
-	sizeAbisses, err := strconv.Atoi(set.Abcisses.Text)
+	sizeAbisses, err := inputSpeed()
 	errorDialog(err)
-	collumnsInc = float64(sizeAbisses) / float64(nbcollumns)
-	ui.RightContainer = *container.NewGridWithColumns(nbcollumns + 1)
+	keys := gridKeys(nbcollumns, float64(sizeAbisses), customGrid)
+	ui.RightContainer = *container.NewGridWithColumns(len(keys))
+	ui.Sliders = make(map[float64]*widget.Slider)
+	ui.LabelSlider = make(map[float64]*canvas.Text)
+	ui.SliderAbs = make(map[float64]*canvas.Text)
+	ui.Histogram = make(map[float64]*fyne.Container)
 
-	increment := 1.0
 	sizeOrdonnee, err := strconv.Atoi(set.OrdonneesMax.Text)
 	errorDialog(err)
 
-	for i := 0; i < nbcollumns+1; i++ {
+	for _, increment := range keys {
 		currentInc := increment
 
 		rawAccel.DataBindingFloat[currentInc] = binding.NewFloat()
 		min, _ := strconv.ParseFloat(set.OrdonneesMin.Text, 8)
 		ui.Sliders[currentInc] = widget.NewSlider(min, float64(sizeOrdonnee))
 		ui.Sliders[currentInc].Orientation = 1
-		ui.Sliders[currentInc].Step = float64(sizeOrdonnee) / 2160
+		ui.Sliders[currentInc].Step = sliderStep(set.OrdonneesMax.Text)
 		ui.Sliders[currentInc].Refresh()
 		round, err := strconv.Atoi(strconv.FormatFloat(increment, 'f', 0, 64))
 		errorDialog(err)
@@ -203,6 +281,12 @@ func genGraph(loadFromSave bool) {
 		}
 
 		ui.Sliders[currentInc].OnChanged = func(f float64) {
+			if !enforceConstraints(currentInc, f) {
+				return
+			}
+			if !applyingExpression {
+				curveExpression = ""
+			}
 			ui.LabelSlider[currentInc].Text = strconv.FormatFloat(f, 'f', 3, 64)
 			ui.LabelSlider[currentInc].Refresh()
 			min, _ := strconv.ParseFloat(set.OrdonneesMin.Text, 8)
@@ -224,29 +308,35 @@ func genGraph(loadFromSave bool) {
 
 		ui.LabelSlider[currentInc].TextSize = 12
 
-		ui.SliderAbs[currentInc] = canvas.NewText(strconv.FormatFloat(increment, 'f', 0, 64), theme.TextColor())
+		ui.SliderAbs[currentInc] = canvas.NewText(formatSpeed(increment, displayedUnit, displayedDPI), theme.TextColor())
 		ui.SliderAbs[currentInc].TextSize = 12
 
-		splitCont := container.NewVSplit(container.NewPadded(ui.Sliders[currentInc]),
+		ui.Histogram[currentInc] = newHistogramBar(currentInc)
+
+		splitCont := container.NewVSplit(container.NewMax(ui.Histogram[currentInc], container.NewPadded(ui.Sliders[currentInc])),
 			container.NewVBox(container.NewCenter(ui.SliderAbs[currentInc]),
 				container.NewCenter(ui.LabelSlider[currentInc])))
 		splitCont.Offset = 0.99
 
 		ui.RightContainer.Add(splitCont)
-
-		increment = increment + collumnsInc
 	}
 
+	refreshHistogram()
 	ui.RightContainer.Refresh()
+	genGraphY()
+	applyExpression()
+	refreshChart()
+	refreshViolations()
 }
 
 func genAccelRaw() {
-	tpl := &bytes.Buffer{}
-	t, err := template.New("").Parse(string(b))
-	errorDialog(err)
-	err = t.Execute(tpl, rawAccel.Data)
-	errorDialog(err)
-	set.Result.SetText(tpl.String())
+	text, err := renderTemplate(set.Template.Selected, newTemplateContext())
+	if err != nil {
+		text = "Template error: " + err.Error()
+	}
+	set.Result.SetText(text)
+	refreshChart()
+	refreshViolations()
 }
 
 func createMenu() *fyne.MainMenu {
@@ -264,9 +354,58 @@ func createMenu() *fyne.MainMenu {
 		Items: nil, // we will add sub items in next video
 	}
 
-	menuItem.Items = append(menuItem.Items, about)
+	menuItem.Items = append(menuItem.Items,
+		fyne.NewMenuItem("Import CSV...", func() { importCurve(formatCSV) }),
+		fyne.NewMenuItem("Import JSON...", func() { importCurve(formatJSON) }),
+		fyne.NewMenuItem("Export CSV...", checkedExport("Export", func() { exportCurve(formatCSV) })),
+		fyne.NewMenuItem("Export JSON...", checkedExport("Export", func() { exportCurve(formatJSON) })),
+		fyne.NewMenuItem("Export image...", showExportImageDialog),
+		fyne.NewMenuItem("Export Raw Accel settings.json...", checkedExport("Export", exportRawAccelSettings)),
+		fyne.NewMenuItem("Export libinput profile...", checkedExport("Export", showLibinputDialog)),
+		fyne.NewMenuItem("Import Windows .reg...", importWindowsReg),
+		fyne.NewMenuItem("Export Windows .reg...", checkedExport("Export", exportWindowsReg)),
+		fyne.NewMenuItem("Export Linux driver parameters...", checkedExport("Export", showLinuxDriverDialog)),
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Copy share code", copyShareCode),
+		fyne.NewMenuItem("Paste share code...", showPasteShareDialog),
+		fyne.NewMenuItemSeparator(),
+		about,
+	)
+
+	curveMenu := &fyne.Menu{
+		Label: "Curve",
+		Items: []*fyne.MenuItem{
+			fyne.NewMenuItem("Expression...", showExpressionDialog),
+			fyne.NewMenuItem("Smooth...", showSmoothDialog),
+			fyne.NewMenuItem("Fit Raw Accel mode...", showFitDialog),
+			fyne.NewMenuItem("Constraints...", showConstraintsDialog),
+			fyne.NewMenuItemSeparator(),
+			fyne.NewMenuItem("Transform range...", showTransformDialog),
+			fyne.NewMenuItem("Copy selected segment", copySegment),
+			fyne.NewMenuItem("Clear selection", func() {
+				setSelection(0, 0)
+			}),
+			fyne.NewMenuItemSeparator(),
+			fyne.NewMenuItem("Combine profiles...", showArithmeticDialog),
+			fyne.NewMenuItem("Inverse curve...", showInverseDialog),
+			fyne.NewMenuItem("Convert DPI...", showConvertDPIDialog),
+			fyne.NewMenuItem("Convert game acceleration...", showGameAccelDialog),
+		},
+	}
+
+	toolsMenu := &fyne.Menu{
+		Label: "Tools",
+		Items: []*fyne.MenuItem{
+			fyne.NewMenuItem("cm/360 calculator...", showCalculatorDialog),
+			fyne.NewMenuItem("Record motion...", showRecorderDialog),
+			fyne.NewMenuItem("Replay trace...", showReplayDialog),
+			fyne.NewMenuItem("Suggest grid from trace...", showGridDialog),
+			fyne.NewMenuItemSeparator(),
+			fyne.NewMenuItem("Aim test...", showAimTestWindow),
+		},
+	}
 
-	menu := fyne.NewMainMenu(menuItem)
+	menu := fyne.NewMainMenu(menuItem, curveMenu, toolsMenu)
 	return menu
 }
 
@@ -285,22 +424,40 @@ func genUIConfig() *fyne.Container {
 
 func saveConfig() {
 	_ = os.Mkdir("configs/", 0755)
+	cfg, err := yaml.Marshal(currentConfig())
+	errorDialog(err)
+	ioutil.WriteFile("configs/current.yml", cfg, 0755)
+}
+
+func currentConfig() Config {
 	var exportConf Config
-	exportConf.ConfAbcisses = set.Abcisses.Text
+	sizeAbisses, _ := inputSpeed()
+	exportConf.ConfAbcisses = strconv.Itoa(sizeAbisses)
+	exportConf.ConfDPI = set.DPI.Text
+	exportConf.ConfPollingRate = set.PollingRate.Text
+	exportConf.ConfUnit = set.Unit.Selected
 	exportConf.ConfOrdonneesMax = set.OrdonneesMax.Text
 	exportConf.ConfOrdonneesMin = set.OrdonneesMin.Text
 	exportConf.ConfCollumns = set.Collumns.Text
 	exportConf.ConfResult = set.Result.Text
+	exportConf.ConfConstraints = profileConstraints
+	exportConf.ConfGrid = customGrid
+	exportConf.ConfRawAccel = profileSettings
+	exportConf.ConfGraphY = currentValuesY()
+	if profileSettings.SeparateXY {
+		exportConf.ConfYRatio = yRatio
+	}
+	exportConf.ConfExpression = curveExpression
+	if curveExpression != "" {
+		exportConf.ConfExprUnit = curveExpressionUnit
+	}
 	exportConf.ConfGraph = make(map[float64]float64)
 	for key, value := range ui.Sliders {
 		if value.Value != 0 {
 			exportConf.ConfGraph[key] = value.Value
 		}
 	}
-
-	cfg, err := yaml.Marshal(exportConf)
-	errorDialog(err)
-	ioutil.WriteFile("configs/current.yml", cfg, 0755)
+	return exportConf
 }
 
 func listConfigs() []string {
@@ -322,12 +479,34 @@ func listConfigs() []string {
 }
 
 func loadConfig(conf string) {
-	cfg, _ := ioutil.ReadFile("configs/" + conf)
-	yaml.Unmarshal(cfg, &importConf)
-	set.Abcisses.Text = importConf.ConfAbcisses
-	if set.Abcisses.Text == "" {
-		set.Abcisses.Text = "250"
+	readProfile(conf)
+	applyConfig()
+	fyneApp.Window.SetTitle(windowTitle())
+}
+
+// Push importConf into the settings and rebuild the sliders
+func applyConfig() {
+	set.DPI.Text = importConf.ConfDPI
+	if set.DPI.Text == "" {
+		set.DPI.Text = strconv.Itoa(defaultDPI)
+	}
+	set.DPI.Refresh()
+	set.PollingRate.Text = importConf.ConfPollingRate
+	if set.PollingRate.Text == "" {
+		set.PollingRate.Text = strconv.Itoa(defaultPollingRate)
+	}
+	set.PollingRate.Refresh()
+	set.Unit.Selected = importConf.ConfUnit
+	if set.Unit.Selected == "" {
+		set.Unit.Selected = unitCountsMs
+	}
+	set.Unit.Refresh()
+	displayedUnit, displayedDPI = set.Unit.Selected, settingsDPI()
+	sizeAbisses, err := strconv.ParseFloat(importConf.ConfAbcisses, 64)
+	if err != nil {
+		sizeAbisses = 250
 	}
+	set.Abcisses.Text = formatSpeed(sizeAbisses, displayedUnit, displayedDPI)
 	set.Abcisses.Refresh()
 	set.OrdonneesMax.Text = importConf.ConfOrdonneesMax
 	if set.OrdonneesMax.Text == "" {
@@ -346,6 +525,11 @@ func loadConfig(conf string) {
 	set.Collumns.Refresh()
 	set.Result.Text = importConf.ConfResult
 	set.Result.Refresh()
+	profileConstraints = importConf.ConfConstraints
+	customGrid = importConf.ConfGrid
+	applyRawAccelSettings(importConf.ConfRawAccel)
+	applyYAxis(importConf)
+	curveExpression, curveExpressionUnit = importConf.ConfExpression, importConf.ConfExprUnit
 	ui.RightContainer.Refresh()
 	genGraph(true)
 }
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"io/ioutil"
+	"math"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+func readConfig(conf string) (Config, error) {
+	var cfg Config
+	data, err := ioutil.ReadFile("configs/" + conf)
+	if err != nil {
+		return cfg, err
+	}
+	err = yaml.Unmarshal(data, &cfg)
+	return cfg, err
+}
+
+// Grid of a stored profile, built the same way genGraph builds the sliders
+func configCurve(cfg Config) []curvePoint {
+	nbcollumns, err := strconv.Atoi(cfg.ConfCollumns)
+	if err != nil || nbcollumns <= 0 {
+		nbcollumns = 15
+	}
+	sizeAbisses, err := strconv.Atoi(cfg.ConfAbcisses)
+	if err != nil {
+		sizeAbisses = 250
+	}
+	min, _ := strconv.ParseFloat(cfg.ConfOrdonneesMin, 64)
+
+	var points []curvePoint
+	for _, x := range gridKeys(nbcollumns, float64(sizeAbisses), cfg.ConfGrid) {
+		y, ok := cfg.ConfGraph[x]
+		if !ok || y == 0 {
+			y = min
+		}
+		points = append(points, curvePoint{X: x, Y: y})
+	}
+	return points
+}
+
+// Copy of base with the values of points, ordinate range widened if needed
+func curveConfig(base Config, points []curvePoint) Config {
+	cfg := base
+	cfg.ConfResult = ""
+	cfg.ConfExpression = ""
+	cfg.ConfGraph = make(map[float64]float64)
+	min, _ := strconv.ParseFloat(base.ConfOrdonneesMin, 64)
+	max, _ := strconv.Atoi(base.ConfOrdonneesMax)
+	lowest := min
+	for _, p := range points {
+		cfg.ConfGraph[p.X] = p.Y
+		lowest = math.Min(lowest, p.Y)
+		if p.Y > float64(max) {
+			max = int(math.Ceil(p.Y))
+		}
+	}
+	cfg.ConfOrdonneesMax = strconv.Itoa(max)
+	if lowest < min {
+		// Points at Ratio Min are not exported, the lowest one has to stay above it
+		step := sliderStep(cfg.ConfOrdonneesMax)
+		min = math.Max(math.Floor((lowest-step)*1000)/1000, 0)
+		cfg.ConfOrdonneesMin = strconv.FormatFloat(min, 'f', -1, 64)
+	}
+	return cfg
+}
+
+// Linear interpolation between points, constant outside of the curve
+func interpolate(points []curvePoint, x float64) float64 {
+	if len(points) == 0 {
+		return 0
+	}
+	if x <= points[0].X {
+		return points[0].Y
+	}
+	for i := 1; i < len(points); i++ {
+		if x <= points[i].X {
+			a, b := points[i-1], points[i]
+			if b.X == a.X {
+				return b.Y
+			}
+			return a.Y + (b.Y-a.Y)*(x-a.X)/(b.X-a.X)
+		}
+	}
+	return points[len(points)-1].Y
+}
+
+// Open a profile that is not saved yet
+func openProfile(cfg Config, name string) {
+	importConf = cfg
+	currentProfile = name
+	rawAccel.Data = map[int]string{}
+	applyConfig()
+	genAccelRaw()
+	fyneApp.Window.SetTitle(windowTitle())
+}
+
+// Replaces importConf with a stored profile. The fields left out of its file
+// are empty, not the ones of the previous profile.
+func readProfile(conf string) {
+	importConf, _ = readConfig(conf)
+	currentProfile = conf
+	rawAccel.Data = map[int]string{}
+}
+
+func windowTitle() string {
+	title := "Raw Accel Data generator by Nicolas HYPOLITE"
+	if currentProfile != "" {
+		title += " - " + currentProfile
+	}
+	return title
+}
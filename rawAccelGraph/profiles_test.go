@@ -0,0 +1,76 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestReadProfileDoesNotLeak(t *testing.T) {
+	dir, err := ioutil.TempDir("", "profiles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	wd, _ := os.Getwd()
+	defer os.Chdir(wd)
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer func(conf Config, profile string, data map[int]string) {
+		importConf, currentProfile, rawAccel.Data = conf, profile, data
+	}(importConf, currentProfile, rawAccel.Data)
+
+	first := Config{
+		ConfAbcisses:     "60",
+		ConfCollumns:     "3",
+		ConfOrdonneesMin: "0",
+		ConfOrdonneesMax: "3",
+		ConfGraph:        map[float64]float64{1: 1, 5: 1.5, 61: 2},
+		ConfGrid:         []float64{1, 5, 61},
+		ConfRawAccel:     RawAccelSettings{ByComponent: true, SeparateXY: true},
+		ConfGraphY:       map[float64]float64{1: 1, 5: 1.2, 61: 1.5},
+		ConfYRatio:       0.5,
+		ConfExpression:   "1 + x/100",
+		ConfExprUnit:     unitInchS,
+	}
+	second := Config{
+		ConfAbcisses:     "40",
+		ConfCollumns:     "2",
+		ConfOrdonneesMin: "0",
+		ConfOrdonneesMax: "2",
+		ConfGraph:        map[float64]float64{1: 1, 21: 1.2},
+	}
+	if err := writeConfig("first.yml", first); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeConfig("second.yml", second); err != nil {
+		t.Fatal(err)
+	}
+
+	readProfile("first.yml")
+	if len(importConf.ConfGrid) != 3 {
+		t.Fatalf("first profile grid %v, want %v", importConf.ConfGrid, first.ConfGrid)
+	}
+	rawAccel.Data = map[int]string{5: "5,1.5;"}
+
+	readProfile("second.yml")
+	if currentProfile != "second.yml" {
+		t.Errorf("current profile %q, want second.yml", currentProfile)
+	}
+	if importConf.ConfGrid != nil {
+		t.Errorf("grid %v kept from the first profile", importConf.ConfGrid)
+	}
+	if importConf.ConfGraphY != nil || importConf.ConfYRatio != 0 || importConf.ConfRawAccel != (RawAccelSettings{}) {
+		t.Errorf("Y curve %v, ratio %v, settings %+v kept from the first profile", importConf.ConfGraphY, importConf.ConfYRatio, importConf.ConfRawAccel)
+	}
+	if importConf.ConfExpression != "" || importConf.ConfExprUnit != "" {
+		t.Errorf("expression %q in %s kept from the first profile", importConf.ConfExpression, importConf.ConfExprUnit)
+	}
+	if len(importConf.ConfGraph) != 2 || importConf.ConfGraph[5] != 0 {
+		t.Errorf("graph %v, want %v", importConf.ConfGraph, second.ConfGraph)
+	}
+	if len(rawAccel.Data) != 0 {
+		t.Errorf("data %v kept from the first profile", rawAccel.Data)
+	}
+}
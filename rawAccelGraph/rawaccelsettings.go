@@ -0,0 +1,345 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	rawAccelVersion = "1.6.1"
+	// Raw Accel rejects bigger lookup tables
+	rawAccelMaxPoints = 257
+)
+
+// Raw Accel profile settings around the lookup table
+type RawAccelSettings struct {
+	SensMultiplier float64
+	YXRatio        float64
+	Rotation       float64
+	AngleSnap      float64
+	SpeedCap       float64
+	InputCap       float64
+	OutputCap      float64
+	// Accel applied per axis instead of on the combined speed
+	ByComponent bool
+	LPNorm      float64
+	DomainX     float64
+	DomainY     float64
+	RangeX      float64
+	RangeY      float64
+	// Vertical axis with its own curve, only in by component mode
+	SeparateXY bool
+}
+
+var profileSettings = defaultRawAccelSettings()
+
+func defaultRawAccelSettings() RawAccelSettings {
+	return RawAccelSettings{
+		SensMultiplier: 1,
+		YXRatio:        1,
+		LPNorm:         2,
+		DomainX:        1,
+		DomainY:        1,
+		RangeX:         1,
+		RangeY:         1,
+	}
+}
+
+// Same ranges as the Raw Accel validation
+func (s RawAccelSettings) validate() []string {
+	var errs []string
+	positive := []struct {
+		name  string
+		value float64
+	}{
+		{"Sensitivity multiplier", s.SensMultiplier},
+		{"Y/X ratio", s.YXRatio},
+		{"LP norm", s.LPNorm},
+		{"Domain X weight", s.DomainX},
+		{"Domain Y weight", s.DomainY},
+		{"Range X weight", s.RangeX},
+		{"Range Y weight", s.RangeY},
+	}
+	for _, p := range positive {
+		if p.value <= 0 {
+			errs = append(errs, p.name+" must be positive")
+		}
+	}
+	for _, p := range []struct {
+		name  string
+		value float64
+	}{
+		{"Speed cap", s.SpeedCap},
+		{"Input cap", s.InputCap},
+		{"Output cap", s.OutputCap},
+	} {
+		if p.value < 0 {
+			errs = append(errs, p.name+" must be 0 (off) or positive")
+		}
+	}
+	if s.AngleSnap < 0 || s.AngleSnap > 45 {
+		errs = append(errs, "Angle snapping must be between 0 and 45 degrees")
+	}
+	if s.Rotation < -180 || s.Rotation > 180 {
+		errs = append(errs, "Rotation must be between -180 and 180 degrees")
+	}
+	if s.SeparateXY && !s.ByComponent {
+		errs = append(errs, "Separate X/Y curves need the by component mode")
+	}
+	return errs
+}
+
+func validateTable(name string, table []curvePoint) []string {
+	var errs []string
+	if len(table) > rawAccelMaxPoints {
+		errs = append(errs, fmt.Sprintf("%s table has %d points, Raw Accel accepts %d", name, len(table), rawAccelMaxPoints))
+	}
+	for i, p := range table {
+		if p.X <= 0 || p.Y <= 0 {
+			errs = append(errs, fmt.Sprintf("%s table point %s has to be positive", name, formatFloat(p.X, 0)))
+		}
+		if i > 0 && p.X <= table[i-1].X {
+			errs = append(errs, fmt.Sprintf("%s table speeds have to increase", name))
+			break
+		}
+	}
+	return errs
+}
+
+type rawAccelXY struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+type rawAccelArgs struct {
+	Mode         string     `json:"mode"`
+	Gain         bool       `json:"Gain / Velocity"`
+	InputOffset  float64    `json:"inputOffset"`
+	OutputOffset float64    `json:"outputOffset"`
+	Cap          rawAccelXY `json:"Cap / Jump"`
+	CapMode      string     `json:"Cap mode"`
+	Data         []float64  `json:"data"`
+}
+
+// The key of Whole has a quote encoding/json does not accept in tags
+const rawAccelWholeKey = "Whole/combined accel (set false for 'by component' mode)"
+
+type rawAccelProfile struct {
+	Name           string       `json:"name"`
+	Whole          bool         `json:"-"`
+	LPNorm         float64      `json:"lpNorm"`
+	Domain         rawAccelXY   `json:"Stretches domain for horizontal vs vertical inputs"`
+	Range          rawAccelXY   `json:"Stretches accel range for horizontal vs vertical inputs"`
+	Horizontal     rawAccelArgs `json:"Whole or horizontal accel parameters"`
+	Vertical       rawAccelArgs `json:"Vertical accel parameters"`
+	SensMultiplier float64      `json:"Sensitivity multiplier"`
+	YXRatio        float64      `json:"Y/X ratio"`
+	LRRatio        float64      `json:"L/R ratio"`
+	UDRatio        float64      `json:"U/D ratio"`
+	Rotation       float64      `json:"Degrees of rotation"`
+	AngleSnap      float64      `json:"Degrees of angle snapping"`
+	SpeedCap       float64      `json:"Input Speed Cap"`
+}
+
+func (p rawAccelProfile) MarshalJSON() ([]byte, error) {
+	type profile rawAccelProfile
+	data, err := json.Marshal(profile(p))
+	if err != nil {
+		return nil, err
+	}
+	key, _ := json.Marshal(rawAccelWholeKey)
+	whole := fmt.Sprintf("{%s:%t,", key, p.Whole)
+	return append([]byte(whole), data[1:]...), nil
+}
+
+type rawAccelDevice struct {
+	Disable bool `json:"disable"`
+	// 0 keeps the table in counts/ms
+	DPI         float64 `json:"DPI (normalizes input speed unit: counts/ms -> in/s)"`
+	PollingRate float64 `json:"Polling rate Hz (keep at 0 for automatic adjustment)"`
+}
+
+type rawAccelFile struct {
+	Version  string            `json:"version"`
+	Device   rawAccelDevice    `json:"defaultDeviceConfig"`
+	Profiles []rawAccelProfile `json:"profiles"`
+	Devices  []interface{}     `json:"devices"`
+}
+
+func (s RawAccelSettings) capMode() string {
+	switch {
+	case s.InputCap > 0 && s.OutputCap > 0:
+		return "in_out"
+	case s.InputCap > 0:
+		return "input"
+	}
+	return "output"
+}
+
+// The tables are used as sensitivity, not velocity
+func (s RawAccelSettings) args(table []curvePoint) rawAccelArgs {
+	data := make([]float64, 0, 2*len(table))
+	for _, p := range table {
+		data = append(data, p.X, p.Y)
+	}
+	return rawAccelArgs{
+		Mode:    "lut",
+		Cap:     rawAccelXY{X: s.InputCap, Y: s.OutputCap},
+		CapMode: s.capMode(),
+		Data:    data,
+	}
+}
+
+// settings.json with one profile, the vertical table is only used with SeparateXY
+func writeRawAccelSettings(w io.Writer, name string, s RawAccelSettings, x, y []curvePoint) error {
+	errs := append(s.validate(), validateTable("X", x)...)
+	if s.SeparateXY {
+		errs = append(errs, validateTable("Y", y)...)
+	} else {
+		y = x
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid settings: %s", errs[0])
+	}
+	profile := rawAccelProfile{
+		Name:           name,
+		Whole:          !s.ByComponent,
+		LPNorm:         s.LPNorm,
+		Domain:         rawAccelXY{X: s.DomainX, Y: s.DomainY},
+		Range:          rawAccelXY{X: s.RangeX, Y: s.RangeY},
+		Horizontal:     s.args(x),
+		Vertical:       s.args(y),
+		SensMultiplier: s.SensMultiplier,
+		YXRatio:        s.YXRatio,
+		LRRatio:        1,
+		UDRatio:        1,
+		Rotation:       s.Rotation,
+		AngleSnap:      s.AngleSnap,
+		SpeedCap:       s.SpeedCap,
+	}
+	file := rawAccelFile{
+		Version:  rawAccelVersion,
+		Profiles: []rawAccelProfile{profile},
+		Devices:  []interface{}{},
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(file)
+}
+
+func profileName() string {
+	if currentProfile == "" {
+		return "default"
+	}
+	return currentProfile
+}
+
+func exportRawAccelSettings() {
+	dialog.ShowFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil || writer == nil {
+			errorDialog(err)
+			return
+		}
+		defer writer.Close()
+		// Built like the settings command builds them from the saved profile
+		cfg := currentConfig()
+		errorDialog(writeRawAccelSettings(writer, profileName(), profileSettings, configTable(cfg), configTableY(cfg)))
+	}, fyneApp.Window)
+}
+
+// Entries of the settings tab, refreshed when a profile is loaded
+var settingsTabRefresh func()
+
+func rawAccelSettingsTab() fyne.CanvasObject {
+	status := widget.NewLabel("")
+	status.Wrapping = fyne.TextWrapWord
+	validate := func() {
+		errs := profileSettings.validate()
+		if len(errs) == 0 {
+			status.SetText("Settings are valid")
+			return
+		}
+		text := ""
+		for _, e := range errs {
+			text += e + "\n"
+		}
+		status.SetText(text)
+	}
+
+	var refreshers []func()
+	field := func(value *float64) *widget.Entry {
+		entry := widget.NewEntry()
+		entry.OnChanged = func(s string) {
+			v, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				status.SetText("Invalid number: " + s)
+				return
+			}
+			*value = v
+			validate()
+		}
+		refreshers = append(refreshers, func() {
+			entry.SetText(strconv.FormatFloat(*value, 'f', -1, 64))
+		})
+		return entry
+	}
+	check := func(label string, value *bool) *widget.Check {
+		c := widget.NewCheck(label, func(b bool) {
+			*value = b
+			validate()
+		})
+		refreshers = append(refreshers, func() {
+			c.SetChecked(*value)
+		})
+		return c
+	}
+
+	s := &profileSettings
+	form := widget.NewForm(
+		widget.NewFormItem("Sensitivity multiplier", field(&s.SensMultiplier)),
+		widget.NewFormItem("Y/X ratio", field(&s.YXRatio)),
+		widget.NewFormItem("Rotation (degrees)", field(&s.Rotation)),
+		widget.NewFormItem("Angle snapping (degrees)", field(&s.AngleSnap)),
+		widget.NewFormItem("Input speed cap (0 = off)", field(&s.SpeedCap)),
+		widget.NewFormItem("Input cap (0 = off)", field(&s.InputCap)),
+		widget.NewFormItem("Output cap (0 = off)", field(&s.OutputCap)),
+		widget.NewFormItem("Mode", check("By component instead of whole", &s.ByComponent)),
+		widget.NewFormItem("LP norm", field(&s.LPNorm)),
+		widget.NewFormItem("Domain weight X", field(&s.DomainX)),
+		widget.NewFormItem("Domain weight Y", field(&s.DomainY)),
+		widget.NewFormItem("Range weight X", field(&s.RangeX)),
+		widget.NewFormItem("Range weight Y", field(&s.RangeY)),
+	)
+	settingsTabRefresh = func() {
+		for _, refresh := range refreshers {
+			refresh()
+		}
+		validate()
+	}
+	settingsTabRefresh()
+
+	exportBtn := widget.NewButton("Export settings.json...", checkedExport("Export", exportRawAccelSettings))
+	resetBtn := widget.NewButton("Defaults", func() {
+		profileSettings = defaultRawAccelSettings()
+		settingsTabRefresh()
+	})
+	return container.NewVScroll(container.NewVBox(form, status, container.NewHBox(exportBtn, resetBtn)))
+}
+
+func applyRawAccelSettings(s RawAccelSettings) {
+	if s == (RawAccelSettings{}) {
+		s = defaultRawAccelSettings()
+	}
+	profileSettings = s
+	if settingsTabRefresh != nil {
+		settingsTabRefresh()
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestRawAccelSettingsValidate(t *testing.T) {
+	if errs := defaultRawAccelSettings().validate(); len(errs) != 0 {
+		t.Errorf("defaults invalid: %v", errs)
+	}
+	tests := []struct {
+		name   string
+		change func(s *RawAccelSettings)
+		msg    string
+	}{
+		{"zero multiplier", func(s *RawAccelSettings) { s.SensMultiplier = 0 }, "Sensitivity multiplier must be positive"},
+		{"negative LP norm", func(s *RawAccelSettings) { s.LPNorm = -2 }, "LP norm must be positive"},
+		{"negative cap", func(s *RawAccelSettings) { s.OutputCap = -1 }, "Output cap must be 0 (off) or positive"},
+		{"angle snapping", func(s *RawAccelSettings) { s.AngleSnap = 46 }, "Angle snapping"},
+		{"rotation", func(s *RawAccelSettings) { s.Rotation = -181 }, "Rotation"},
+		{"separate whole", func(s *RawAccelSettings) { s.SeparateXY = true }, "by component"},
+	}
+	for _, test := range tests {
+		s := defaultRawAccelSettings()
+		test.change(&s)
+		errs := s.validate()
+		if len(errs) != 1 || !strings.Contains(errs[0], test.msg) {
+			t.Errorf("%s: got %v, want one error with %q", test.name, errs, test.msg)
+		}
+	}
+
+	s := defaultRawAccelSettings()
+	s.ByComponent, s.SeparateXY, s.AngleSnap, s.Rotation = true, true, 45, 180
+	if errs := s.validate(); len(errs) != 0 {
+		t.Errorf("limits refused: %v", errs)
+	}
+}
+
+func TestValidateTable(t *testing.T) {
+	long := make([]curvePoint, rawAccelMaxPoints+1)
+	for i := range long {
+		long[i] = curvePoint{X: float64(i + 1), Y: 1}
+	}
+	tests := []struct {
+		name  string
+		table []curvePoint
+		msg   string
+	}{
+		{"valid", []curvePoint{{X: 1, Y: 1}, {X: 5, Y: 1.5}}, ""},
+		{"empty", nil, ""},
+		{"too long", long, "258 points, Raw Accel accepts 257"},
+		{"zero speed", []curvePoint{{X: 0, Y: 1}, {X: 5, Y: 1.5}}, "X table point 0 has to be positive"},
+		{"zero sensitivity", []curvePoint{{X: 1, Y: 1}, {X: 5, Y: 0}}, "X table point 5 has to be positive"},
+		{"not increasing", []curvePoint{{X: 1, Y: 1}, {X: 5, Y: 1}, {X: 5, Y: 2}}, "X table speeds have to increase"},
+	}
+	for _, test := range tests {
+		errs := validateTable("X", test.table)
+		if test.msg == "" {
+			if len(errs) != 0 {
+				t.Errorf("%s: got %v", test.name, errs)
+			}
+			continue
+		}
+		if len(errs) != 1 || !strings.Contains(errs[0], test.msg) {
+			t.Errorf("%s: got %v, want one error with %q", test.name, errs, test.msg)
+		}
+	}
+}
+
+func TestWriteRawAccelSettings(t *testing.T) {
+	x := []curvePoint{{X: 1, Y: 1}, {X: 10, Y: 1.5}}
+	y := []curvePoint{{X: 1, Y: 0.5}, {X: 20, Y: 2}}
+	s := defaultRawAccelSettings()
+	s.InputCap = 30
+
+	var buf bytes.Buffer
+	if err := writeRawAccelSettings(&buf, "fast", s, x, y); err != nil {
+		t.Fatal(err)
+	}
+	// The key of Whole comes first and keeps its quotes unescaped
+	if !strings.Contains(buf.String(), `"profiles": [`+"\n"+`    {`+"\n"+`      "Whole/combined accel (set false for 'by component' mode)": true,`) {
+		t.Errorf("no Whole key at the start of the profile:\n%s", buf.String())
+	}
+	var file map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &file); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+	if file["version"] != rawAccelVersion {
+		t.Errorf("version %v", file["version"])
+	}
+	profile := file["profiles"].([]interface{})[0].(map[string]interface{})
+	if profile["name"] != "fast" || profile[rawAccelWholeKey] != true || profile["Sensitivity multiplier"] != 1.0 {
+		t.Errorf("profile %v", profile)
+	}
+	horizontal := profile["Whole or horizontal accel parameters"].(map[string]interface{})
+	if horizontal["mode"] != "lut" || horizontal["Cap mode"] != "input" {
+		t.Errorf("horizontal %v", horizontal)
+	}
+	// Without separate curves the vertical axis gets the X table
+	want := []interface{}{1.0, 1.0, 10.0, 1.5}
+	for _, key := range []string{"Whole or horizontal accel parameters", "Vertical accel parameters"} {
+		data := profile[key].(map[string]interface{})["data"].([]interface{})
+		if len(data) != len(want) {
+			t.Errorf("%s data %v, want %v", key, data, want)
+			continue
+		}
+		for i := range want {
+			if data[i] != want[i] {
+				t.Errorf("%s data %v, want %v", key, data, want)
+				break
+			}
+		}
+	}
+
+	s.ByComponent, s.SeparateXY = true, true
+	buf.Reset()
+	if err := writeRawAccelSettings(&buf, "fast", s, x, y); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(buf.Bytes(), &file); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+	profile = file["profiles"].([]interface{})[0].(map[string]interface{})
+	if profile[rawAccelWholeKey] != false {
+		t.Errorf("Whole %v in by component mode", profile[rawAccelWholeKey])
+	}
+	vertical := profile["Vertical accel parameters"].(map[string]interface{})["data"].([]interface{})
+	if len(vertical) != 4 || vertical[1] != 0.5 || vertical[2] != 20.0 {
+		t.Errorf("vertical data %v, want the Y table", vertical)
+	}
+
+	if err := writeRawAccelSettings(&buf, "fast", s, x, []curvePoint{{X: 2, Y: 1}, {X: 1, Y: 1}}); err == nil {
+		t.Error("no error for an invalid Y table")
+	}
+	s.SensMultiplier = 0
+	if err := writeRawAccelSettings(&buf, "fast", s, x, y); err == nil || !strings.Contains(err.Error(), "Sensitivity multiplier") {
+		t.Errorf("got %v, want the validation error", err)
+	}
+}
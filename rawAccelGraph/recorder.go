@@ -0,0 +1,245 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Area recording the pointer motion hovering it. The pointer position is
+// already scaled by the OS, so the trace is only in counts with the OS
+// sensitivity at 1:1 and its acceleration disabled.
+type capturePad struct {
+	widget.BaseWidget
+	Recording bool
+	Events    []motionEvent
+	OnEvent   func()
+
+	start time.Time
+	last  fyne.Position
+	// No delta for the first event after entering the pad
+	inside bool
+}
+
+func newCapturePad() *capturePad {
+	pad := &capturePad{}
+	pad.ExtendBaseWidget(pad)
+	return pad
+}
+
+func (p *capturePad) CreateRenderer() fyne.WidgetRenderer {
+	background := canvas.NewRectangle(theme.InputBackgroundColor())
+	hint := canvas.NewText("Move the mouse in this area", theme.PlaceHolderColor())
+	return widget.NewSimpleRenderer(container.NewMax(background, container.NewCenter(hint)))
+}
+
+func (p *capturePad) MinSize() fyne.Size {
+	return fyne.NewSize(450, 300)
+}
+
+func (p *capturePad) Start() {
+	p.Events = nil
+	p.inside = false
+	p.start = time.Now()
+	p.Recording = true
+}
+
+func (p *capturePad) MouseIn(e *desktop.MouseEvent) {
+	p.last = e.Position
+	p.inside = true
+}
+
+func (p *capturePad) MouseMoved(e *desktop.MouseEvent) {
+	if !p.inside {
+		p.MouseIn(e)
+		return
+	}
+	scale := float64(fyneApp.Window.Canvas().Scale())
+	dx := float64(e.Position.X-p.last.X) * scale
+	dy := float64(e.Position.Y-p.last.Y) * scale
+	p.last = e.Position
+	if !p.Recording {
+		return
+	}
+	p.Events = append(p.Events, motionEvent{Time: time.Since(p.start), DX: dx, DY: dy})
+	if p.OnEvent != nil {
+		p.OnEvent()
+	}
+}
+
+func (p *capturePad) MouseOut() {
+	p.inside = false
+}
+
+func showRecorderDialog() {
+	pad := newCapturePad()
+	status := widget.NewLabel("")
+	showStatus := func(events []motionEvent) {
+		if len(events) == 0 {
+			status.SetText("No motion recorded")
+			return
+		}
+		text := fmt.Sprintf("%d events over %s", len(events), events[len(events)-1].Time.Round(time.Millisecond))
+		if rate := estimatePollingRate(events); rate > 0 {
+			text += fmt.Sprintf(", about %s Hz", formatFloat(rate, 0))
+		}
+		status.SetText(text)
+	}
+	showStatus(loadedTrace)
+	pad.OnEvent = func() {
+		if len(pad.Events)%50 == 0 {
+			showStatus(pad.Events)
+		}
+	}
+
+	var recordBtn *widget.Button
+	recordBtn = widget.NewButton("Start", func() {
+		if pad.Recording {
+			pad.Recording = false
+			recordBtn.SetText("Start")
+			showStatus(pad.Events)
+			if len(pad.Events) > 1 {
+				setTrace(pad.Events)
+			}
+			return
+		}
+		pad.Start()
+		recordBtn.SetText("Stop")
+	})
+	saveBtn := widget.NewButton("Save trace...", func() {
+		if len(loadedTrace) == 0 {
+			errorDialog(fmt.Errorf("no trace recorded"))
+			return
+		}
+		dialog.ShowFileSave(func(writer fyne.URIWriteCloser, err error) {
+			if err != nil || writer == nil {
+				errorDialog(err)
+				return
+			}
+			defer writer.Close()
+			errorDialog(writeTrace(writer, loadedTrace))
+		}, fyneApp.Window)
+	})
+	loadBtn := widget.NewButton("Load trace...", func() {
+		dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil || reader == nil {
+				errorDialog(err)
+				return
+			}
+			defer reader.Close()
+			events, err := readTrace(reader)
+			if err != nil {
+				errorDialog(err)
+				return
+			}
+			setTrace(events)
+			showStatus(events)
+		}, fyneApp.Window)
+	})
+
+	// Raw counts straight from the kernel, before any desktop acceleration
+	var devices []string
+	paths := map[string]string{}
+	for _, d := range listEvdevDevices() {
+		devices = append(devices, d.String())
+		paths[d.String()] = d.Path
+	}
+	device := widget.NewSelect(devices, nil)
+	device.PlaceHolder = "evdev device"
+	var deviceLock sync.Mutex
+	var stopDevice chan struct{}
+	// Asks the device recording to end, its goroutine then updates the dialog
+	stopRecording := func() bool {
+		deviceLock.Lock()
+		defer deviceLock.Unlock()
+		if stopDevice == nil {
+			return false
+		}
+		close(stopDevice)
+		stopDevice = nil
+		return true
+	}
+	var deviceBtn *widget.Button
+	deviceBtn = widget.NewButton("Record device", func() {
+		if stopRecording() {
+			// Enabled again once the recording has ended
+			deviceBtn.Disable()
+			return
+		}
+		path, ok := paths[device.Selected]
+		if !ok {
+			errorDialog(fmt.Errorf("select an evdev device"))
+			return
+		}
+		stop := make(chan struct{})
+		deviceLock.Lock()
+		stopDevice = stop
+		deviceLock.Unlock()
+		deviceBtn.SetText("Stop device")
+		status.SetText("Recording " + path)
+		// Also ends on its own when the device can't be read or is unplugged
+		go func() {
+			events, err := recordEvdev(path, stop)
+			deviceLock.Lock()
+			if stopDevice == stop {
+				stopDevice = nil
+			}
+			deviceLock.Unlock()
+			if len(events) > 1 {
+				setTrace(events)
+			}
+			deviceBtn.SetText("Record device")
+			deviceBtn.Enable()
+			showStatus(events)
+			errorDialog(err)
+		}()
+	})
+	logBtn := widget.NewButton("Load evdev log...", func() {
+		dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil || reader == nil {
+				errorDialog(err)
+				return
+			}
+			defer reader.Close()
+			events, err := readEvdevFile(reader)
+			if err != nil {
+				errorDialog(err)
+				return
+			}
+			motion := evdevMotion(events)
+			if len(motion) < 2 {
+				errorDialog(fmt.Errorf("no relative motion in this log"))
+				return
+			}
+			setTrace(motion)
+			showStatus(motion)
+		}, fyneApp.Window)
+	})
+	clearBtn := widget.NewButton("Clear histogram", func() {
+		setTrace(nil)
+		showStatus(nil)
+	})
+
+	content := container.NewBorder(
+		widget.NewLabel("Set the OS pointer speed to 1:1 without acceleration, press Start and move in the area."),
+		container.NewVBox(
+			status,
+			container.NewHBox(recordBtn, saveBtn, loadBtn, clearBtn),
+			container.NewBorder(nil, nil, nil, container.NewHBox(deviceBtn, logBtn), device),
+		),
+		nil, nil, pad)
+	recorderDial := dialog.NewCustom("Record motion", "Close", content, fyneApp.Window)
+	recorderDial.SetOnClosed(func() {
+		stopRecording()
+	})
+	recorderDial.Resize(fyne.NewSize(600, 500))
+	recorderDial.Show()
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+const replayBands = 6
+
+// Cursor paths of a replayed trace, positions are cumulative counts
+type replayResult struct {
+	Raw    []curvePoint
+	Output []curvePoint
+	Bands  []replayBand
+}
+
+// Distance moved by the hand and by the cursor for speeds in [From, To)
+type replayBand struct {
+	From, To float64
+	Samples  int
+	Raw      float64
+	Output   float64
+}
+
+func (b replayBand) Ratio() float64 {
+	if b.Raw == 0 {
+		return 0
+	}
+	return b.Output / b.Raw
+}
+
+// Lookup table as Raw Accel receives it, sliders left at the minimum are not exported
+func dataTable(data map[int]string) []curvePoint {
+	var table []curvePoint
+	for x, value := range data {
+		y, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			continue
+		}
+		table = append(table, curvePoint{X: float64(x), Y: y})
+	}
+	sort.Slice(table, func(i, j int) bool { return table[i].X < table[j].X })
+	return table
+}
+
+// Same table for a stored profile
+func configTable(cfg Config) []curvePoint {
+	min, _ := strconv.ParseFloat(cfg.ConfOrdonneesMin, 64)
+	return curveTable(configCurve(cfg), min)
+}
+
+// Raw Accel interpolates linearly between the points of a sensitivity table
+// and keeps the first and last values outside of it
+func tableSens(table []curvePoint, speed float64) float64 {
+	if len(table) == 0 {
+		return 1
+	}
+	return interpolate(table, speed)
+}
+
+// Speed band edges spread evenly up to the fastest sample
+func speedBands(events []motionEvent, count int) []float64 {
+	max := 0.0
+	for _, s := range traceSpeeds(events) {
+		max = math.Max(max, s.Speed)
+	}
+	if max == 0 {
+		max = 1
+	}
+	edges := make([]float64, count+1)
+	for i := range edges {
+		edges[i] = max * float64(i) / float64(count)
+	}
+	return edges
+}
+
+// Run every sample through the table. Samples after a pause use the polling
+// interval since the device time between reports is unknown. In the by
+// component mode each axis goes through its own table at the speed of its
+// own component, tableY is only used there.
+func replayTrace(events []motionEvent, table, tableY []curvePoint, byComponent bool, edges []float64, pollingRate float64) replayResult {
+	var result replayResult
+	for i := 1; i < len(edges); i++ {
+		result.Bands = append(result.Bands, replayBand{From: edges[i-1], To: edges[i]})
+	}
+	interval := 1000 / pollingRate
+	var raw, output curvePoint
+	result.Raw = append(result.Raw, raw)
+	result.Output = append(result.Output, output)
+	for i, e := range events {
+		ms := interval
+		if i > 0 {
+			if dt := e.Time - events[i-1].Time; dt > 0 && dt <= traceIdle {
+				ms = float64(dt) / float64(time.Millisecond)
+			}
+		}
+		distance := math.Hypot(e.DX, e.DY)
+		speed := distance / ms
+		sensX := tableSens(table, speed)
+		sensY := sensX
+		if byComponent {
+			sensX = tableSens(table, math.Abs(e.DX)/ms)
+			sensY = tableSens(tableY, math.Abs(e.DY)/ms)
+		}
+		raw = curvePoint{X: raw.X + e.DX, Y: raw.Y - e.DY}
+		output = curvePoint{X: output.X + e.DX*sensX, Y: output.Y - e.DY*sensY}
+		result.Raw = append(result.Raw, raw)
+		result.Output = append(result.Output, output)
+
+		band := sort.SearchFloat64s(edges, speed) - 1
+		if band < 0 {
+			band = 0
+		}
+		if band >= len(result.Bands) {
+			band = len(result.Bands) - 1
+		}
+		if band >= 0 {
+			result.Bands[band].Samples++
+			result.Bands[band].Raw += distance
+			result.Bands[band].Output += math.Hypot(e.DX*sensX, e.DY*sensY)
+		}
+	}
+	return result
+}
+
+func replaySummary(a, b replayResult, nameA, nameB string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%-16s %8s %10s", "Speed", "Samples", nameA)
+	if b.Bands != nil {
+		fmt.Fprintf(&sb, " %10s %8s", nameB, "Change")
+	}
+	sb.WriteString("\n")
+	for i, band := range a.Bands {
+		from := formatSpeed(band.From, displayedUnit, displayedDPI)
+		to := formatSpeed(band.To, displayedUnit, displayedDPI)
+		fmt.Fprintf(&sb, "%-16s %8d %10s", from+"-"+to, band.Samples, formatFloat(band.Ratio(), 3))
+		if b.Bands != nil {
+			other := b.Bands[i].Ratio()
+			change := ""
+			if band.Ratio() != 0 {
+				change = formatFloat((other/band.Ratio()-1)*100, 1) + "%"
+			}
+			fmt.Fprintf(&sb, " %10s %8s", formatFloat(other, 3), change)
+		}
+		sb.WriteString("\n")
+	}
+	total := func(r replayResult) float64 {
+		var raw, output float64
+		for _, band := range r.Bands {
+			raw += band.Raw
+			output += band.Output
+		}
+		if raw == 0 {
+			return 0
+		}
+		return output / raw
+	}
+	fmt.Fprintf(&sb, "%-16s %8s %10s", "Total", "", formatFloat(total(a), 3))
+	if b.Bands != nil {
+		fmt.Fprintf(&sb, " %10s", formatFloat(total(b), 3))
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+func showReplayDialog() {
+	if len(loadedTrace) < 2 {
+		errorDialog(fmt.Errorf("record or load a trace first in Tools > Record motion"))
+		return
+	}
+	none := "(none)"
+	compare := widget.NewSelect(append([]string{none}, listConfigs()...), nil)
+	rawChart := newCurveChart()
+	outputChart := newCurveChart()
+	summary := widget.NewLabel("")
+	summary.TextStyle = fyne.TextStyle{Monospace: true}
+
+	update := func() {
+		edges := speedBands(loadedTrace, replayBands)
+		rate := settingsPollingRate()
+		current := replayTrace(loadedTrace, dataTable(rawAccel.Data), currentTableY(), profileSettings.ByComponent, edges, rate)
+		rawChart.SetSeries(chartSeries{Points: current.Raw, Color: theme.DisabledColor(), Width: 1})
+		series := []chartSeries{{Points: current.Output, Color: theme.PrimaryColor(), Width: 1}}
+
+		var other replayResult
+		if compare.Selected != none && compare.Selected != "" {
+			cfg, err := readConfig(compare.Selected)
+			if err != nil {
+				errorDialog(err)
+				return
+			}
+			other = replayTrace(loadedTrace, configTable(cfg), configTableY(cfg), cfg.ConfRawAccel.ByComponent, edges, rate)
+			series = append(series, chartSeries{Points: other.Output, Color: overlayColor, Width: 1})
+		}
+		outputChart.SetSeries(series...)
+		summary.SetText(replaySummary(current, other, "Current", compare.Selected))
+	}
+	compare.OnChanged = func(string) { update() }
+	compare.SetSelected(none)
+
+	paths := container.NewGridWithColumns(2,
+		container.NewBorder(widget.NewLabel("Raw path"), nil, nil, nil, rawChart),
+		container.NewBorder(widget.NewLabel("Cursor path"), nil, nil, nil, outputChart),
+	)
+	content := container.NewBorder(
+		widget.NewForm(widget.NewFormItem("Compare with", compare)),
+		summary, nil, nil, paths)
+	replayDial := dialog.NewCustom("Replay trace", "Close", content, fyneApp.Window)
+	replayDial.Resize(fyne.NewSize(750, 600))
+	replayDial.Show()
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSpeedBands(t *testing.T) {
+	events := []motionEvent{
+		{Time: 0},
+		{Time: time.Millisecond, DX: 2},
+		{Time: 3 * time.Millisecond, DX: 12},
+		// Pauses don't count as a slow sample
+		{Time: 503 * time.Millisecond, DX: 100},
+		{Time: 504 * time.Millisecond, DY: -12},
+	}
+	edges := speedBands(events, 4)
+	want := []float64{0, 3, 6, 9, 12}
+	if len(edges) != len(want) {
+		t.Fatalf("got %v, want %v", edges, want)
+	}
+	for i := range want {
+		if !almostEqual(edges[i], want[i]) {
+			t.Errorf("edge %d = %v, want %v", i, edges[i], want[i])
+		}
+	}
+
+	// Without motion the bands still cover something
+	edges = speedBands(nil, 2)
+	if len(edges) != 3 || edges[2] != 1 {
+		t.Errorf("no motion: got %v, want [0 0.5 1]", edges)
+	}
+}
+
+func TestReplayTrace(t *testing.T) {
+	table := []curvePoint{{X: 1, Y: 1}, {X: 10, Y: 2}}
+	events := []motionEvent{
+		// First sample, the polling interval of 1 ms is used: 5 counts/ms
+		{Time: 0, DX: 3, DY: 4},
+		// 2 ms later: 0.5 count/ms, below the table
+		{Time: 2 * time.Millisecond, DX: -1},
+		// After a pause: 20 counts/ms in 1 ms, above the table
+		{Time: time.Second, DY: -20},
+	}
+	edges := []float64{0, 4, 8}
+	result := replayTrace(events, table, nil, false, edges, 1000)
+
+	sens := 1 + 4.0/9
+	wantRaw := []curvePoint{{X: 0, Y: 0}, {X: 3, Y: -4}, {X: 2, Y: -4}, {X: 2, Y: 16}}
+	wantOutput := []curvePoint{{X: 0, Y: 0}, {X: 3 * sens, Y: -4 * sens}, {X: 3*sens - 1, Y: -4 * sens}, {X: 3*sens - 1, Y: 40 - 4*sens}}
+	if len(result.Raw) != len(wantRaw) || len(result.Output) != len(wantOutput) {
+		t.Fatalf("got %d raw and %d output points, want %d", len(result.Raw), len(result.Output), len(wantRaw))
+	}
+	for i := range wantRaw {
+		if !almostEqual(result.Raw[i].X, wantRaw[i].X) || !almostEqual(result.Raw[i].Y, wantRaw[i].Y) {
+			t.Errorf("raw %d = %v, want %v", i, result.Raw[i], wantRaw[i])
+		}
+		if !almostEqual(result.Output[i].X, wantOutput[i].X) || !almostEqual(result.Output[i].Y, wantOutput[i].Y) {
+			t.Errorf("output %d = %v, want %v", i, result.Output[i], wantOutput[i])
+		}
+	}
+
+	// The slow sample goes to the first band, the fast ones to the last
+	wantBands := []replayBand{
+		{From: 0, To: 4, Samples: 1, Raw: 1, Output: 1},
+		{From: 4, To: 8, Samples: 2, Raw: 25, Output: 5*sens + 40},
+	}
+	if len(result.Bands) != len(wantBands) {
+		t.Fatalf("got %d bands, want %d", len(result.Bands), len(wantBands))
+	}
+	for i, want := range wantBands {
+		got := result.Bands[i]
+		if got.From != want.From || got.To != want.To || got.Samples != want.Samples ||
+			!almostEqual(got.Raw, want.Raw) || !almostEqual(got.Output, want.Output) {
+			t.Errorf("band %d = %+v, want %+v", i, got, want)
+		}
+	}
+	if !almostEqual(result.Bands[1].Ratio(), (5*sens+40)/25) || (replayBand{}).Ratio() != 0 {
+		t.Errorf("ratio = %v", result.Bands[1].Ratio())
+	}
+}
+
+func TestReplayTraceEmptyTable(t *testing.T) {
+	events := []motionEvent{{Time: 0, DX: 3}, {Time: time.Millisecond, DX: 4}}
+	result := replayTrace(events, nil, nil, false, speedBands(events, 2), 1000)
+	// Without a table the cursor follows the hand
+	if last := result.Output[len(result.Output)-1]; last != (curvePoint{X: 7}) {
+		t.Errorf("output ends at %v, want {7 0}", last)
+	}
+}
+
+func TestReplayTraceByComponent(t *testing.T) {
+	table := []curvePoint{{X: 1, Y: 1}, {X: 10, Y: 2}}
+	tableY := []curvePoint{{X: 1, Y: 0.5}, {X: 10, Y: 0.5}}
+	// 3 counts/ms horizontally and 4 vertically
+	events := []motionEvent{{Time: 0, DX: 3, DY: 4}}
+	result := replayTrace(events, table, tableY, true, []float64{0, 10}, 1000)
+
+	sensX := 1 + 2.0/9
+	want := curvePoint{X: 3 * sensX, Y: -4 * 0.5}
+	if got := result.Output[1]; !almostEqual(got.X, want.X) || !almostEqual(got.Y, want.Y) {
+		t.Errorf("output = %v, want %v", got, want)
+	}
+	if got := result.Bands[0].Output; !almostEqual(got, math.Hypot(want.X, want.Y)) {
+		t.Errorf("band output = %v, want %v", got, math.Hypot(want.X, want.Y))
+	}
+
+	// The whole mode ignores tableY and uses the speed of the vector
+	whole := replayTrace(events, table, tableY, false, []float64{0, 10}, 1000)
+	sens := 1 + 4.0/9
+	if got := whole.Output[1]; !almostEqual(got.X, 3*sens) || !almostEqual(got.Y, -4*sens) {
+		t.Errorf("whole mode output = %v, want {%v %v}", got, 3*sens, -4*sens)
+	}
+}
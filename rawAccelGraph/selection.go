@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	transformScale   = "Scale by %"
+	transformOffset  = "Add offset"
+	transformRamp    = "Linear ramp"
+	transformFlatten = "Flatten to average"
+	transformMirror  = "Mirror"
+	transformPaste   = "Paste segment"
+)
+
+var transformOps = []string{transformScale, transformOffset, transformRamp, transformFlatten, transformMirror, transformPaste}
+
+// Range of abscissas used by the bulk operations
+var selection struct {
+	From float64
+	To   float64
+}
+
+// Values of the last copied segment
+var segmentClipboard []float64
+
+func setSelection(from, to float64) {
+	if from > to {
+		from, to = to, from
+	}
+	selection.From, selection.To = from, to
+	if ui.Chart != nil {
+		ui.Chart.SetSelection(from, to)
+	}
+}
+
+// Selected range, the whole curve when nothing is selected
+func selectedRange(points []curvePoint) (float64, float64) {
+	if selection.From == selection.To && len(points) > 0 {
+		return points[0].X, points[len(points)-1].X
+	}
+	return selection.From, selection.To
+}
+
+func transformCurve(points []curvePoint, op string, param, from, to float64) ([]float64, error) {
+	values := curveValues(points)
+	start, end := curveRange(points, from, to)
+	if start < 0 {
+		return values, fmt.Errorf("no point between %s and %s %s", formatSpeed(from, displayedUnit, displayedDPI),
+			formatSpeed(to, displayedUnit, displayedDPI), displayedUnit)
+	}
+	segment := values[start : end+1]
+	switch op {
+	case transformScale:
+		for i := range segment {
+			segment[i] *= 1 + param/100
+		}
+	case transformOffset:
+		for i := range segment {
+			segment[i] += param
+		}
+	case transformRamp:
+		first, last := segment[0], segment[len(segment)-1]
+		span := points[end].X - points[start].X
+		for i := range segment {
+			if span > 0 {
+				segment[i] = first + (last-first)*(points[start+i].X-points[start].X)/span
+			}
+		}
+	case transformFlatten:
+		sum := 0.0
+		for _, v := range segment {
+			sum += v
+		}
+		for i := range segment {
+			segment[i] = sum / float64(len(segment))
+		}
+	case transformMirror:
+		for i, j := 0, len(segment)-1; i < j; i, j = i+1, j-1 {
+			segment[i], segment[j] = segment[j], segment[i]
+		}
+	case transformPaste:
+		if len(segmentClipboard) == 0 {
+			return values, fmt.Errorf("no segment copied")
+		}
+		copy(segment, resampleValues(segmentClipboard, len(segment)))
+	}
+	return values, nil
+}
+
+// Linear resampling of a list of values to a new length
+func resampleValues(values []float64, size int) []float64 {
+	result := make([]float64, size)
+	for i := range result {
+		if size == 1 || len(values) == 1 {
+			result[i] = values[0]
+			continue
+		}
+		pos := float64(i) * float64(len(values)-1) / float64(size-1)
+		lo := int(pos)
+		if lo >= len(values)-1 {
+			result[i] = values[len(values)-1]
+			continue
+		}
+		frac := pos - float64(lo)
+		result[i] = values[lo]*(1-frac) + values[lo+1]*frac
+	}
+	return result
+}
+
+func copySegment() {
+	points := currentCurve()
+	from, to := selectedRange(points)
+	start, end := curveRange(points, from, to)
+	if start < 0 {
+		return
+	}
+	segmentClipboard = curveValues(points[start : end+1])
+}
+
+func showTransformDialog() {
+	points := currentCurve()
+	if len(points) == 0 {
+		return
+	}
+	selFrom, selTo := selectedRange(points)
+
+	op := widget.NewSelect(transformOps, nil)
+	op.SetSelected(transformScale)
+	param := widget.NewEntry()
+	param.Text = "8"
+	from := widget.NewEntry()
+	from.Text = formatSpeed(selFrom, displayedUnit, displayedDPI)
+	to := widget.NewEntry()
+	to.Text = formatSpeed(selTo, displayedUnit, displayedDPI)
+	status := widget.NewLabel("")
+
+	chart := newCurveChart()
+	var preview []float64
+	update := func() {
+		fromX := parseSpeedEntry(from.Text, selFrom)
+		toX := parseSpeedEntry(to.Text, selTo)
+		value, _ := strconv.ParseFloat(param.Text, 64)
+		var err error
+		preview, err = transformCurve(points, op.Selected, value, fromX, toX)
+		if err != nil {
+			status.SetText(err.Error())
+		} else {
+			status.SetText("")
+		}
+		chart.SetSeries(
+			chartSeries{Points: points, Color: theme.DisabledColor(), Width: 1},
+			chartSeries{Points: withValues(points, preview), Color: theme.PrimaryColor(), Width: 2},
+		)
+		chart.SetSelection(fromX, toX)
+	}
+	op.OnChanged = func(string) { update() }
+	param.OnChanged = func(string) { update() }
+	from.OnChanged = func(string) { update() }
+	to.OnChanged = func(string) { update() }
+	update()
+
+	form := widget.NewForm(
+		widget.NewFormItem("Operation", op),
+		widget.NewFormItem("Value", param),
+		widget.NewFormItem("From ("+displayedUnit+")", from),
+		widget.NewFormItem("To ("+displayedUnit+")", to),
+	)
+	transformDial := dialog.NewCustomConfirm("Transform range", "Apply", "Cancel", container.NewVBox(form, status, chart), func(ok bool) {
+		if ok {
+			applyCurve(preview)
+		}
+	}, fyneApp.Window)
+	transformDial.Resize(fyne.NewSize(500, 480))
+	transformDial.Show()
+}
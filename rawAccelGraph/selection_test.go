@@ -0,0 +1,74 @@
+package main
+
+import "testing"
+
+func selectionCurve() []curvePoint {
+	return []curvePoint{{X: 1, Y: 1}, {X: 11, Y: 1.2}, {X: 21, Y: 1.6}, {X: 31, Y: 1.4}, {X: 41, Y: 2}}
+}
+
+func TestTransformCurve(t *testing.T) {
+	segmentClipboard = []float64{3, 5}
+	defer func() { segmentClipboard = nil }()
+	tests := []struct {
+		op    string
+		param float64
+		want  []float64
+	}{
+		{transformScale, 50, []float64{1, 1.8, 2.4, 2.1, 2}},
+		{transformOffset, -0.2, []float64{1, 1, 1.4, 1.2, 2}},
+		{transformRamp, 0, []float64{1, 1.2, 1.3, 1.4, 2}},
+		{transformFlatten, 0, []float64{1, 1.4, 1.4, 1.4, 2}},
+		{transformMirror, 0, []float64{1, 1.4, 1.6, 1.2, 2}},
+		{transformPaste, 0, []float64{1, 3, 4, 5, 2}},
+	}
+	for _, test := range tests {
+		got, err := transformCurve(selectionCurve(), test.op, test.param, 5, 35)
+		if err != nil {
+			t.Errorf("%s: %v", test.op, err)
+			continue
+		}
+		for i := range test.want {
+			if !almostEqual(got[i], test.want[i]) {
+				t.Errorf("%s: value %d = %v, want %v", test.op, i, got[i], test.want[i])
+			}
+		}
+	}
+}
+
+func TestTransformCurveErrors(t *testing.T) {
+	if _, err := transformCurve(selectionCurve(), transformScale, 10, 2, 5); err == nil {
+		t.Error("no error for a range without point")
+	}
+	segmentClipboard = nil
+	if _, err := transformCurve(selectionCurve(), transformPaste, 0, 1, 41); err == nil {
+		t.Error("no error for an empty segment clipboard")
+	}
+}
+
+func TestResampleValues(t *testing.T) {
+	got := resampleValues([]float64{1, 3}, 5)
+	want := []float64{1, 1.5, 2, 2.5, 3}
+	for i := range want {
+		if !almostEqual(got[i], want[i]) {
+			t.Errorf("value %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+	if got := resampleValues([]float64{2}, 3); got[2] != 2 {
+		t.Errorf("single value resampled to %v", got)
+	}
+}
+
+func TestParseSpeedEntry(t *testing.T) {
+	displayedUnit, displayedDPI = unitInchS, 800
+	defer func() { displayedUnit, displayedDPI = unitCountsMs, defaultDPI }()
+	// 16.67 counts/ms shows as 20.8 in/s, the exact value is kept while unchanged
+	if got := parseSpeedEntry(formatSpeed(16.67, unitInchS, 800), 16.67); got != 16.67 {
+		t.Errorf("unchanged entry = %v, want 16.67", got)
+	}
+	if got := parseSpeedEntry("10", 1); !almostEqual(got, 8) {
+		t.Errorf("10 in/s = %v counts/ms, want 8", got)
+	}
+	if got := parseSpeedEntry("abc", 3); got != 3 {
+		t.Errorf("invalid entry = %v, want the fallback", got)
+	}
+}
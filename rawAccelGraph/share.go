@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"gopkg.in/yaml.v3"
+)
+
+// A share code is sharePrefix followed by the base64url encoding of:
+// version byte, crc32 of the profile, deflated yaml profile
+const (
+	sharePrefix  = "rag-"
+	shareVersion = 1
+	shareHeader  = 5
+)
+
+type shareProfile struct {
+	Name    string
+	Created string
+	Config  Config
+}
+
+func encodeShareCode(name string, cfg Config) (string, error) {
+	// The Raw Accel text is rebuilt from the points on import
+	cfg.ConfResult = ""
+	data, err := yaml.Marshal(shareProfile{Name: name, Created: time.Now().Format("2006-01-02"), Config: cfg})
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte(shareVersion)
+	_ = binary.Write(&buf, binary.BigEndian, crc32.ChecksumIEEE(data))
+	w, err := flate.NewWriter(&buf, flate.BestCompression)
+	if err != nil {
+		return "", err
+	}
+	if _, err := w.Write(data); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	return sharePrefix + base64.RawURLEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+func decodeShareCode(code string) (shareProfile, error) {
+	var profile shareProfile
+	code = strings.Join(strings.Fields(code), "")
+	if !strings.HasPrefix(code, sharePrefix) {
+		return profile, fmt.Errorf("not a share code")
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(code, sharePrefix))
+	if err != nil {
+		return profile, fmt.Errorf("share code is damaged: %w", err)
+	}
+	if len(raw) < shareHeader {
+		return profile, fmt.Errorf("share code is too short")
+	}
+	if raw[0] != shareVersion {
+		return profile, fmt.Errorf("share code version %d is not supported, this version reads %d", raw[0], shareVersion)
+	}
+	data, err := ioutil.ReadAll(flate.NewReader(bytes.NewReader(raw[shareHeader:])))
+	if err != nil {
+		return profile, fmt.Errorf("share code is damaged: %w", err)
+	}
+	if crc32.ChecksumIEEE(data) != binary.BigEndian.Uint32(raw[1:shareHeader]) {
+		return profile, fmt.Errorf("share code checksum does not match, it was probably truncated")
+	}
+	err = yaml.Unmarshal(data, &profile)
+	return profile, err
+}
+
+func copyShareCode() {
+	code, err := encodeShareCode(strings.TrimSuffix(currentProfile, " (unsaved)"), currentConfig())
+	if err != nil {
+		errorDialog(err)
+		return
+	}
+	fyneApp.Window.Clipboard().SetContent(code)
+	dialog.ShowInformation("Share code", fmt.Sprintf("Share code copied to the clipboard (%d characters)", len(code)), fyneApp.Window)
+}
+
+func showPasteShareDialog() {
+	entry := widget.NewMultiLineEntry()
+	entry.Wrapping = fyne.TextWrapBreak
+	if clip := fyneApp.Window.Clipboard().Content(); strings.HasPrefix(strings.TrimSpace(clip), sharePrefix) {
+		entry.SetText(clip)
+	}
+	entry.SetPlaceHolder(sharePrefix + "...")
+	pasteDial := dialog.NewCustomConfirm("Paste share code", "Open", "Cancel", entry, func(ok bool) {
+		if !ok {
+			return
+		}
+		profile, err := decodeShareCode(entry.Text)
+		if err != nil {
+			errorDialog(err)
+			return
+		}
+		name := profile.Name
+		if name == "" {
+			name = "shared"
+		}
+		openProfile(profile.Config, name+" (unsaved)")
+	}, fyneApp.Window)
+	pasteDial.Resize(fyne.NewSize(500, 300))
+	pasteDial.Show()
+}
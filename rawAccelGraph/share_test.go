@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func shareConfig() Config {
+	return Config{
+		ConfAbcisses:     "60",
+		ConfCollumns:     "4",
+		ConfOrdonneesMin: "0",
+		ConfOrdonneesMax: "3",
+		ConfDPI:          "1600",
+		ConfGraph:        map[float64]float64{1: 1, 5: 1.2, 12: 1.5, 30: 1.75, 61: 2},
+		ConfGrid:         []float64{1, 5, 12, 30, 61},
+		ConfResult:       "0,0;\n",
+	}
+}
+
+func TestShareCodeRoundTrip(t *testing.T) {
+	cfg := shareConfig()
+	code, err := encodeShareCode("fast flicks", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(code, sharePrefix) {
+		t.Fatalf("code %q does not start with %q", code, sharePrefix)
+	}
+	// Chat clients wrap long codes
+	wrapped := code[:20] + "\n  " + code[20:40] + " " + code[40:]
+	profile, err := decodeShareCode(wrapped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile.Name != "fast flicks" || profile.Created == "" {
+		t.Errorf("name %q created %q", profile.Name, profile.Created)
+	}
+	got := profile.Config
+	if got.ConfAbcisses != cfg.ConfAbcisses || got.ConfCollumns != cfg.ConfCollumns || got.ConfDPI != cfg.ConfDPI {
+		t.Errorf("got %+v, want %+v", got, cfg)
+	}
+	for key, v := range cfg.ConfGraph {
+		if got.ConfGraph[key] != v {
+			t.Errorf("point at %v = %v, want %v", key, got.ConfGraph[key], v)
+		}
+	}
+	if len(got.ConfGrid) != len(cfg.ConfGrid) || got.ConfGrid[2] != 12 {
+		t.Errorf("grid %v, want %v", got.ConfGrid, cfg.ConfGrid)
+	}
+	if got.ConfResult != "" {
+		t.Errorf("the Raw Accel text was shared: %q", got.ConfResult)
+	}
+}
+
+func TestShareCodeBadCRC(t *testing.T) {
+	code, err := encodeShareCode("crc", shareConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(code, sharePrefix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw[2] ^= 0xff
+	_, err = decodeShareCode(sharePrefix + base64.RawURLEncoding.EncodeToString(raw))
+	if err == nil || !strings.Contains(err.Error(), "checksum") {
+		t.Errorf("got %v, want a checksum error", err)
+	}
+}
+
+func TestShareCodeErrors(t *testing.T) {
+	code, err := encodeShareCode("errors", shareConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(code, sharePrefix))
+	raw[0] = shareVersion + 1
+	tests := []struct {
+		name string
+		code string
+		msg  string
+	}{
+		{"truncated", code[:len(code)-10], "damaged"},
+		{"truncated header", code[:len(sharePrefix)+4], "too short"},
+		{"no prefix", strings.TrimPrefix(code, sharePrefix), "not a share code"},
+		{"not base64", sharePrefix + "abc!def", "damaged"},
+		{"newer version", sharePrefix + base64.RawURLEncoding.EncodeToString(raw), "not supported"},
+		{"empty", "", "not a share code"},
+	}
+	for _, test := range tests {
+		_, err := decodeShareCode(test.code)
+		if err == nil || !strings.Contains(err.Error(), test.msg) {
+			t.Errorf("%s: got %v, want an error with %q", test.name, err, test.msg)
+		}
+	}
+}
+
+func TestCLIShareCodeNeedsConfig(t *testing.T) {
+	code, err := encodeShareCode("shared", shareConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Checked before anything is written to configs/current.yml
+	if err := cliShare([]string{"-code", code}); err == nil || !strings.Contains(err.Error(), "-config") {
+		t.Errorf("got %v, want an error asking for -config", err)
+	}
+}
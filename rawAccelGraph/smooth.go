@@ -0,0 +1,251 @@
+package main
+
+import (
+	"math"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	smoothMovingAverage = "Moving average"
+	smoothGaussian      = "Gaussian"
+	smoothSavitzkyGolay = "Savitzky-Golay"
+	smoothMonotonic     = "Enforce monotonic"
+)
+
+var smoothMethods = []string{smoothMovingAverage, smoothGaussian, smoothSavitzkyGolay, smoothMonotonic}
+
+// Strength goes from 1 (light) to 10 (strong) for every method
+func smoothValues(method string, values []float64, strength float64) []float64 {
+	switch method {
+	case smoothMovingAverage:
+		return movingAverage(values, int(math.Round(strength)))
+	case smoothGaussian:
+		return gaussianSmooth(values, strength)
+	case smoothSavitzkyGolay:
+		return savitzkyGolay(values, int(math.Round(strength))+1, 2)
+	case smoothMonotonic:
+		return blendValues(values, isotonic(values), strength/10)
+	}
+	return append([]float64(nil), values...)
+}
+
+func movingAverage(values []float64, radius int) []float64 {
+	result := make([]float64, len(values))
+	for i := range values {
+		lo, hi := window(i, radius, len(values))
+		sum := 0.0
+		for j := lo; j <= hi; j++ {
+			sum += values[j]
+		}
+		result[i] = sum / float64(hi-lo+1)
+	}
+	return result
+}
+
+func gaussianSmooth(values []float64, sigma float64) []float64 {
+	result := make([]float64, len(values))
+	radius := int(math.Ceil(3 * sigma))
+	for i := range values {
+		lo, hi := window(i, radius, len(values))
+		sum, weights := 0.0, 0.0
+		for j := lo; j <= hi; j++ {
+			d := float64(j - i)
+			w := math.Exp(-d * d / (2 * sigma * sigma))
+			sum += w * values[j]
+			weights += w
+		}
+		result[i] = sum / weights
+	}
+	return result
+}
+
+// Local polynomial fit of the given order around each point, the window is
+// truncated on the edges of the curve
+func savitzkyGolay(values []float64, halfWindow, order int) []float64 {
+	result := make([]float64, len(values))
+	for i := range values {
+		lo, hi := window(i, halfWindow, len(values))
+		var xs, ys []float64
+		for j := lo; j <= hi; j++ {
+			xs = append(xs, float64(j-i))
+			ys = append(ys, values[j])
+		}
+		deg := order
+		if deg > len(xs)-1 {
+			deg = len(xs) - 1
+		}
+		coefs, ok := polyFit(xs, ys, deg)
+		if !ok {
+			result[i] = values[i]
+			continue
+		}
+		result[i] = coefs[0]
+	}
+	return result
+}
+
+// Pool adjacent violators, gives the closest non-decreasing curve
+func isotonic(values []float64) []float64 {
+	type block struct {
+		sum   float64
+		count int
+	}
+	var blocks []block
+	for _, v := range values {
+		blocks = append(blocks, block{v, 1})
+		for len(blocks) > 1 {
+			last := blocks[len(blocks)-1]
+			prev := blocks[len(blocks)-2]
+			if prev.sum/float64(prev.count) <= last.sum/float64(last.count) {
+				break
+			}
+			blocks = blocks[:len(blocks)-2]
+			blocks = append(blocks, block{prev.sum + last.sum, prev.count + last.count})
+		}
+	}
+	result := make([]float64, 0, len(values))
+	for _, b := range blocks {
+		for i := 0; i < b.count; i++ {
+			result = append(result, b.sum/float64(b.count))
+		}
+	}
+	return result
+}
+
+func blendValues(from, to []float64, ratio float64) []float64 {
+	ratio = math.Max(0, math.Min(1, ratio))
+	result := make([]float64, len(from))
+	for i := range from {
+		result[i] = from[i] + (to[i]-from[i])*ratio
+	}
+	return result
+}
+
+// Least squares polynomial, coefficients from the constant term up
+func polyFit(xs, ys []float64, deg int) ([]float64, bool) {
+	n := deg + 1
+	a := make([][]float64, n)
+	for r := range a {
+		a[r] = make([]float64, n+1)
+		for c := 0; c < n; c++ {
+			for _, x := range xs {
+				a[r][c] += math.Pow(x, float64(r+c))
+			}
+		}
+		for k, x := range xs {
+			a[r][n] += math.Pow(x, float64(r)) * ys[k]
+		}
+	}
+	return solveLinear(a)
+}
+
+// Gauss-Jordan elimination on an augmented matrix
+func solveLinear(a [][]float64) ([]float64, bool) {
+	n := len(a)
+	for col := 0; col < n; col++ {
+		pivot := col
+		for r := col + 1; r < n; r++ {
+			if math.Abs(a[r][col]) > math.Abs(a[pivot][col]) {
+				pivot = r
+			}
+		}
+		if math.Abs(a[pivot][col]) < 1e-12 {
+			return nil, false
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+		for r := 0; r < n; r++ {
+			if r == col {
+				continue
+			}
+			f := a[r][col] / a[col][col]
+			for c := col; c <= n; c++ {
+				a[r][c] -= f * a[col][c]
+			}
+		}
+	}
+	result := make([]float64, n)
+	for i := range result {
+		result[i] = a[i][n] / a[i][i]
+	}
+	return result, true
+}
+
+func window(i, radius, size int) (int, int) {
+	lo, hi := i-radius, i+radius
+	if lo < 0 {
+		lo = 0
+	}
+	if hi > size-1 {
+		hi = size - 1
+	}
+	return lo, hi
+}
+
+// Smooth only the points between from and to, the rest of the curve is kept
+func smoothCurve(points []curvePoint, method string, strength, from, to float64) []float64 {
+	values := curveValues(points)
+	start, end := curveRange(points, from, to)
+	if start < 0 {
+		return values
+	}
+	smoothed := smoothValues(method, values[start:end+1], strength)
+	copy(values[start:], smoothed)
+	return values
+}
+
+func showSmoothDialog() {
+	points := currentCurve()
+	if len(points) == 0 {
+		return
+	}
+
+	method := widget.NewSelect(smoothMethods, nil)
+	method.SetSelected(smoothMovingAverage)
+	strength := widget.NewSlider(1, 10)
+	strength.Step = 0.5
+	strength.Value = 2
+	strengthLabel := widget.NewLabel("2")
+	selFrom, selTo := selectedRange(points)
+	from := widget.NewEntry()
+	from.Text = formatSpeed(selFrom, displayedUnit, displayedDPI)
+	to := widget.NewEntry()
+	to.Text = formatSpeed(selTo, displayedUnit, displayedDPI)
+
+	chart := newCurveChart()
+	var preview []float64
+	update := func() {
+		strengthLabel.SetText(formatFloat(strength.Value, 1))
+		fromX := parseSpeedEntry(from.Text, selFrom)
+		toX := parseSpeedEntry(to.Text, selTo)
+		preview = smoothCurve(points, method.Selected, strength.Value, fromX, toX)
+		chart.SetSeries(
+			chartSeries{Points: points, Color: theme.DisabledColor(), Width: 1},
+			chartSeries{Points: withValues(points, preview), Color: theme.PrimaryColor(), Width: 2},
+		)
+		chart.SetSelection(fromX, toX)
+	}
+	method.OnChanged = func(string) { update() }
+	strength.OnChanged = func(float64) { update() }
+	from.OnChanged = func(string) { update() }
+	to.OnChanged = func(string) { update() }
+	update()
+
+	form := widget.NewForm(
+		widget.NewFormItem("Method", method),
+		widget.NewFormItem("Strength", container.NewBorder(nil, nil, nil, strengthLabel, strength)),
+		widget.NewFormItem("From ("+displayedUnit+")", from),
+		widget.NewFormItem("To ("+displayedUnit+")", to),
+	)
+	smoothDial := dialog.NewCustomConfirm("Smooth curve", "Apply", "Cancel", container.NewVBox(form, chart), func(ok bool) {
+		if ok {
+			applyCurve(preview)
+		}
+	}, fyneApp.Window)
+	smoothDial.Resize(fyne.NewSize(500, 450))
+	smoothDial.Show()
+}
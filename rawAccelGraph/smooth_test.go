@@ -0,0 +1,91 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSmoothKeepsConstantCurve(t *testing.T) {
+	values := []float64{1.5, 1.5, 1.5, 1.5, 1.5, 1.5}
+	for _, method := range smoothMethods {
+		for i, v := range smoothValues(method, values, 3) {
+			if !almostEqual(v, 1.5) {
+				t.Errorf("%s: value %d = %v, want 1.5", method, i, v)
+			}
+		}
+	}
+}
+
+func TestMovingAverage(t *testing.T) {
+	got := movingAverage([]float64{0, 3, 6, 9}, 1)
+	want := []float64{1.5, 3, 6, 7.5}
+	for i := range want {
+		if !almostEqual(got[i], want[i]) {
+			t.Errorf("value %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestGaussianSmoothFlattensSpike(t *testing.T) {
+	got := gaussianSmooth([]float64{1, 1, 1, 5, 1, 1, 1}, 1)
+	if got[3] >= 5 || got[3] <= 1 || got[2] <= 1 {
+		t.Errorf("spike not spread: %v", got)
+	}
+	if !almostEqual(got[2], got[4]) {
+		t.Errorf("not symmetric: %v", got)
+	}
+}
+
+func TestSavitzkyGolayKeepsQuadratic(t *testing.T) {
+	var values []float64
+	for i := 0; i < 10; i++ {
+		x := float64(i)
+		values = append(values, 1+0.2*x+0.03*x*x)
+	}
+	for i, v := range savitzkyGolay(values, 3, 2) {
+		if math.Abs(v-values[i]) > 1e-6 {
+			t.Errorf("value %d = %v, want %v", i, v, values[i])
+		}
+	}
+}
+
+func TestIsotonicIsNonDecreasing(t *testing.T) {
+	values := []float64{1, 1.4, 1.2, 1.3, 2, 1.8, 1.9, 2.5}
+	got := isotonic(values)
+	for i := 1; i < len(got); i++ {
+		if got[i] < got[i-1] {
+			t.Errorf("value %d = %v is below %v", i, got[i], got[i-1])
+		}
+	}
+	// Pooling keeps the mean of the pooled values
+	sum, gotSum := 0.0, 0.0
+	for i := range values {
+		sum += values[i]
+		gotSum += got[i]
+	}
+	if !almostEqual(sum, gotSum) {
+		t.Errorf("sum %v, want %v", gotSum, sum)
+	}
+
+	sorted := []float64{1, 1.1, 1.5, 2}
+	for i, v := range isotonic(sorted) {
+		if v != sorted[i] {
+			t.Errorf("sorted value %d changed to %v", i, v)
+		}
+	}
+}
+
+func TestSmoothCurveKeepsOutsideRange(t *testing.T) {
+	var points []curvePoint
+	for i := 0; i < 10; i++ {
+		points = append(points, curvePoint{X: float64(i + 1), Y: float64(i%3) + 1})
+	}
+	for _, method := range smoothMethods {
+		got := smoothCurve(points, method, 2, 4, 7)
+		for i, p := range points {
+			if (p.X < 4 || p.X > 7) && got[i] != p.Y {
+				t.Errorf("%s: point %v changed to %v", method, p.X, got[i])
+			}
+		}
+	}
+}
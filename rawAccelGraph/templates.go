@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const (
+	// Relative to the working directory like configs/
+	templatesDir    = "templates/"
+	builtinTemplate = "Raw Accel (built-in)"
+)
+
+// Everything a template can use, the built-in one only ranges over Data
+type templateContext struct {
+	Data        map[int]string
+	Points      []curvePoint
+	Settings    Config
+	DPI         float64
+	PollingRate float64
+	Unit        string
+	Profile     string
+	Time        time.Time
+	// Vertical curve, same as Data and Points without separate X and Y curves
+	DataY     map[int]string
+	PointsY   []curvePoint
+	SeparateY bool
+}
+
+var templateFuncs = template.FuncMap{
+	"float": formatFloat,
+	"speed": func(counts float64, unit string, dpi float64) string {
+		return formatSpeed(counts, unit, dpi)
+	},
+	"round": func(f float64) int {
+		return int(math.Round(f))
+	},
+	"add": func(a, b float64) float64 { return a + b },
+	"sub": func(a, b float64) float64 { return a - b },
+	"mul": func(a, b float64) float64 { return a * b },
+	"div": func(a, b float64) float64 {
+		if b == 0 {
+			return 0
+		}
+		return a / b
+	},
+	"date": func(layout string, t time.Time) string {
+		return t.Format(layout)
+	},
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+func listTemplates() []string {
+	_ = os.Mkdir(templatesDir, 0755)
+	templates := []string{builtinTemplate}
+	files, err := filepath.Glob(filepath.Join(templatesDir, "*.tmpl"))
+	errorDialog(err)
+	for _, file := range files {
+		templates = append(templates, filepath.Base(file))
+	}
+	return templates
+}
+
+func templateSource(name string) ([]byte, error) {
+	if name == "" || name == builtinTemplate {
+		return b, nil
+	}
+	return ioutil.ReadFile(filepath.Join(templatesDir, name))
+}
+
+func newTemplateContext() templateContext {
+	return templateContext{
+		Data:        rawAccel.Data,
+		Points:      currentCurve(),
+		DataY:       tableData(currentTableY()),
+		PointsY:     currentCurveY(),
+		SeparateY:   profileSettings.SeparateXY,
+		Settings:    currentConfig(),
+		DPI:         settingsDPI(),
+		PollingRate: settingsPollingRate(),
+		Unit:        displayedUnit,
+		Profile:     currentProfile,
+		Time:        time.Now(),
+	}
+}
+
+func renderTemplate(name string, context templateContext) (string, error) {
+	source, err := templateSource(name)
+	if err != nil {
+		return "", err
+	}
+	t, err := template.New(name).Funcs(templateFuncs).Parse(string(source))
+	if err != nil {
+		return "", err
+	}
+	tpl := &bytes.Buffer{}
+	err = t.Execute(tpl, context)
+	return tpl.String(), err
+}
@@ -0,0 +1,27 @@
+package main
+
+import "testing"
+
+func TestBuiltinTemplate(t *testing.T) {
+	context := templateContext{
+		Data:  map[int]string{1: "1.000", 10: "1.500"},
+		DataY: map[int]string{1: "1.000", 10: "1.250"},
+	}
+	got, err := renderTemplate(builtinTemplate, context)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "1,\t1.000;\n10,\t1.500;\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// The Y table is a second block, each one goes in its own Raw Accel field
+	context.SeparateY = true
+	got, err = renderTemplate(builtinTemplate, context)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "X curve\n1,\t1.000;\n10,\t1.500;\n\nY curve\n1,\t1.000;\n10,\t1.250;\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+)
+
+// Pauses longer than this are not counted as movement
+const traceIdle = 100 * time.Millisecond
+
+// Relative motion in counts, Time is measured from the start of the trace
+type motionEvent struct {
+	Time   time.Duration
+	DX, DY float64
+}
+
+type speedSample struct {
+	Speed    float64 // counts/ms
+	Duration time.Duration
+}
+
+// Trace shown as a histogram behind the sliders
+var loadedTrace []motionEvent
+var speedHistogram = map[float64]float64{}
+
+func writeTrace(w io.Writer, events []motionEvent) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"time_us", "dx", "dy"}); err != nil {
+		return err
+	}
+	for _, e := range events {
+		record := []string{
+			strconv.FormatInt(e.Time.Microseconds(), 10),
+			strconv.FormatFloat(e.DX, 'f', -1, 64),
+			strconv.FormatFloat(e.DY, 'f', -1, 64),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func readTrace(r io.Reader) ([]motionEvent, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = 3
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	var events []motionEvent
+	for i, record := range records {
+		us, err := strconv.ParseInt(record[0], 10, 64)
+		if err != nil {
+			if i == 0 {
+				continue // header
+			}
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		dx, err := strconv.ParseFloat(record[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		dy, err := strconv.ParseFloat(record[2], 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		events = append(events, motionEvent{Time: time.Duration(us) * time.Microsecond, DX: dx, DY: dy})
+	}
+	if len(events) < 2 {
+		return nil, fmt.Errorf("trace has less than two events")
+	}
+	return events, nil
+}
+
+// Speed of every event from the time elapsed since the previous one
+func traceSpeeds(events []motionEvent) []speedSample {
+	var samples []speedSample
+	for i := 1; i < len(events); i++ {
+		dt := events[i].Time - events[i-1].Time
+		if dt <= 0 || dt > traceIdle {
+			continue
+		}
+		ms := float64(dt) / float64(time.Millisecond)
+		samples = append(samples, speedSample{Speed: math.Hypot(events[i].DX, events[i].DY) / ms, Duration: dt})
+	}
+	return samples
+}
+
+// Time spent around each key, keys must be sorted. The busiest key is 1.
+func traceHistogram(events []motionEvent, keys []float64) map[float64]float64 {
+	histogram := make(map[float64]float64)
+	if len(keys) == 0 {
+		return histogram
+	}
+	max := 0.0
+	for _, s := range traceSpeeds(events) {
+		key := keys[nearestKey(keys, s.Speed)]
+		histogram[key] += s.Duration.Seconds()
+		max = math.Max(max, histogram[key])
+	}
+	if max > 0 {
+		for key := range histogram {
+			histogram[key] /= max
+		}
+	}
+	return histogram
+}
+
+func nearestKey(keys []float64, x float64) int {
+	best := 0
+	for i, key := range keys {
+		if math.Abs(key-x) < math.Abs(keys[best]-x) {
+			best = i
+		}
+	}
+	return best
+}
+
+// Bar at the bottom of a column, its height follows speedHistogram
+type histogramLayout struct {
+	key float64
+}
+
+func (l histogramLayout) Layout(objects []fyne.CanvasObject, size fyne.Size) {
+	height := size.Height * float32(speedHistogram[l.key])
+	for _, o := range objects {
+		o.Resize(fyne.NewSize(size.Width, height))
+		o.Move(fyne.NewPos(0, size.Height-height))
+	}
+}
+
+func (l histogramLayout) MinSize([]fyne.CanvasObject) fyne.Size {
+	return fyne.NewSize(0, 0)
+}
+
+func newHistogramBar(key float64) *fyne.Container {
+	return container.New(histogramLayout{key}, canvas.NewRectangle(histogramColor))
+}
+
+func setTrace(events []motionEvent) {
+	loadedTrace = events
+	refreshHistogram()
+}
+
+func refreshHistogram() {
+	speedHistogram = traceHistogram(loadedTrace, sliderKeys())
+	for _, bar := range ui.Histogram {
+		bar.Refresh()
+	}
+}
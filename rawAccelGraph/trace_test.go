@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTraceRoundTrip(t *testing.T) {
+	events := []motionEvent{
+		{Time: 0, DX: 1, DY: 0},
+		{Time: 1250 * time.Microsecond, DX: -3, DY: 2.5},
+		{Time: 2 * time.Second, DX: 0.125, DY: -7},
+	}
+	var buf bytes.Buffer
+	if err := writeTrace(&buf, events); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "time_us,dx,dy\n0,1,0\n1250,-3,2.5\n") {
+		t.Errorf("unexpected trace %q", buf.String())
+	}
+	got, err := readTrace(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(events) {
+		t.Fatalf("got %v, want %v", got, events)
+	}
+	for i := range events {
+		if got[i] != events[i] {
+			t.Errorf("event %d = %v, want %v", i, got[i], events[i])
+		}
+	}
+}
+
+func TestReadTraceErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		msg  string
+	}{
+		{"single event", "time_us,dx,dy\n0,1,1\n", "two events"},
+		{"bad time", "0,1,1\nsoon,1,1\n", "line 2"},
+		{"bad dy", "time_us,dx,dy\n0,1,1\n10,1,up\n", "line 3"},
+		{"missing field", "0,1,1\n10,1\n", "wrong number of fields"},
+	}
+	for _, test := range tests {
+		_, err := readTrace(strings.NewReader(test.src))
+		if err == nil || !strings.Contains(err.Error(), test.msg) {
+			t.Errorf("%s: got %v, want an error with %q", test.name, err, test.msg)
+		}
+	}
+}
+
+func TestTraceHistogram(t *testing.T) {
+	ms := time.Millisecond
+	events := []motionEvent{
+		{Time: 0},
+		{Time: 1 * ms, DX: 1},           // 1 count/ms for 1 ms
+		{Time: 2 * ms, DX: 5},           // 5 counts/ms for 1 ms
+		{Time: 3 * ms, DX: 3, DY: 4},    // 5 counts/ms for 1 ms
+		{Time: 6 * ms, DX: 30},          // 10 counts/ms for 3 ms
+		{Time: 300 * ms, DX: 1},         // after a pause, not counted
+		{Time: 301 * ms, DX: 0, DY: 11}, // 11 counts/ms is closest to 10
+	}
+	got := traceHistogram(events, []float64{1, 5, 10})
+	want := map[float64]float64{1: 0.25, 5: 0.5, 10: 1}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for key, value := range want {
+		if !almostEqual(got[key], value) {
+			t.Errorf("bucket %v = %v, want %v", key, got[key], value)
+		}
+	}
+
+	if got := traceHistogram(events, nil); len(got) != 0 {
+		t.Errorf("no keys: got %v", got)
+	}
+	if got := traceHistogram(events[:1], []float64{1}); len(got) != 0 {
+		t.Errorf("single event: got %v", got)
+	}
+}
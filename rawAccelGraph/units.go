@@ -0,0 +1,105 @@
+package main
+
+import (
+	"math"
+	"strconv"
+)
+
+const (
+	unitCountsMs = "counts/ms"
+	unitInchS    = "in/s"
+	unitCmS      = "cm/s"
+
+	defaultDPI         = 800
+	defaultPollingRate = 1000
+)
+
+var speedUnits = []string{unitCountsMs, unitInchS, unitCmS}
+
+// Unit and DPI the Input Speed entry is currently written in
+var displayedUnit = unitCountsMs
+var displayedDPI = float64(defaultDPI)
+
+// Value of one count/ms in the given unit
+func unitFactor(unit string, dpi float64) float64 {
+	if dpi <= 0 {
+		dpi = defaultDPI
+	}
+	switch unit {
+	case unitInchS:
+		return 1000 / dpi
+	case unitCmS:
+		return 1000 / dpi * 2.54
+	}
+	return 1
+}
+
+func settingsDPI() float64 {
+	dpi, err := strconv.ParseFloat(set.DPI.Text, 64)
+	if err != nil || dpi <= 0 {
+		return defaultDPI
+	}
+	return dpi
+}
+
+func configDPI(cfg Config) float64 {
+	dpi, err := strconv.ParseFloat(cfg.ConfDPI, 64)
+	if err != nil || dpi <= 0 {
+		return defaultDPI
+	}
+	return dpi
+}
+
+func settingsPollingRate() float64 {
+	rate, err := strconv.ParseFloat(set.PollingRate.Text, 64)
+	if err != nil || rate <= 0 {
+		return defaultPollingRate
+	}
+	return rate
+}
+
+func formatSpeed(counts float64, unit string, dpi float64) string {
+	if unit == unitCountsMs {
+		return formatFloat(counts, 0)
+	}
+	return formatFloat(counts*unitFactor(unit, dpi), 1)
+}
+
+// Speed typed in the displayed unit, in counts/ms. The fallback is kept while
+// the entry still shows it, formatSpeed rounds it.
+func parseSpeedEntry(text string, fallback float64) float64 {
+	if text == formatSpeed(fallback, displayedUnit, displayedDPI) {
+		return fallback
+	}
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return fallback
+	}
+	return value / unitFactor(displayedUnit, displayedDPI)
+}
+
+// Input Speed entry converted back to counts/ms, the unit Raw Accel expects
+func inputSpeed() (int, error) {
+	value, err := strconv.ParseFloat(set.Abcisses.Text, 64)
+	if err != nil {
+		return 0, err
+	}
+	return int(math.Round(value / unitFactor(displayedUnit, displayedDPI))), nil
+}
+
+// Rewrite the Input Speed entry and the axis labels after a unit or DPI change
+func changeSpeedUnit(unit string, dpi float64) {
+	counts, err := inputSpeed()
+	displayedUnit, displayedDPI = unit, dpi
+	if err == nil {
+		set.Abcisses.SetText(formatSpeed(float64(counts), unit, dpi))
+	}
+	refreshAxisLabels()
+}
+
+func refreshAxisLabels() {
+	for key, label := range ui.SliderAbs {
+		label.Text = formatSpeed(key, displayedUnit, displayedDPI)
+		label.Refresh()
+	}
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+)
+
+const (
+	smoothMouseX = "SmoothMouseXCurve"
+	smoothMouseY = "SmoothMouseYCurve"
+
+	// Enhance Pointer Precision always uses 5 points, the first one is 0,0
+	windowsCurvePoints = 5
+
+	// Approximations of the Windows units: a SmoothMouseXCurve unit is about
+	// 3.5 in/s of hand speed and Y = 3.5 * X gives 1:1 at 100% scaling
+	windowsXUnit    = 3.5
+	windowsOneToOne = 3.5
+)
+
+// Where the 4 free points are placed along the curve
+var windowsCurveSpread = []float64{0.05, 0.2, 0.5, 1}
+
+type windowsCurve struct {
+	X [windowsCurvePoints]float64
+	Y [windowsCurvePoints]float64
+}
+
+func countsToWindowsX(counts, dpi float64) float64 {
+	return counts * unitFactor(unitInchS, dpi) / windowsXUnit
+}
+
+func curveToWindows(points []curvePoint, dpi float64) (windowsCurve, error) {
+	var curve windowsCurve
+	if len(points) < 2 {
+		return curve, fmt.Errorf("at least two points are needed")
+	}
+	last := points[len(points)-1].X
+	for i, spread := range windowsCurveSpread {
+		counts := last * spread
+		curve.X[i+1] = countsToWindowsX(counts, dpi)
+		curve.Y[i+1] = curve.X[i+1] * windowsOneToOne * interpolate(points, counts)
+	}
+	return curve, nil
+}
+
+// Sensitivity Windows applies at a speed, Y is interpolated linearly on X
+func (c windowsCurve) sensitivity(counts, dpi float64) float64 {
+	x := countsToWindowsX(counts, dpi)
+	for i := 1; i < windowsCurvePoints; i++ {
+		if x <= c.X[i] || i == windowsCurvePoints-1 {
+			x0, x1 := c.X[i-1], c.X[i]
+			y0, y1 := c.Y[i-1], c.Y[i]
+			if x1 == x0 {
+				return 0
+			}
+			y := y0 + (y1-y0)*(x-x0)/(x1-x0)
+			if x <= 0 {
+				return (y1 - y0) / (x1 - x0) / windowsOneToOne
+			}
+			return y / x / windowsOneToOne
+		}
+	}
+	return 0
+}
+
+// Sample the Windows curve on the abscissas of grid
+func (c windowsCurve) toCurve(grid []curvePoint, dpi float64) []curvePoint {
+	result := make([]curvePoint, len(grid))
+	for i, p := range grid {
+		result[i] = curvePoint{X: p.X, Y: c.sensitivity(p.X, dpi)}
+	}
+	return result
+}
+
+// 16.16 fixed point values stored on 8 bytes each
+func encodeWindowsValues(values [windowsCurvePoints]float64) ([]byte, error) {
+	data := make([]byte, 8*windowsCurvePoints)
+	for i, v := range values {
+		fixed := math.Round(v * 65536)
+		if fixed < 0 || fixed > math.MaxUint32 {
+			return nil, fmt.Errorf("point %d: %s is outside the 0 to 65536 range Windows can store", i+1, formatFloat(v, 2))
+		}
+		binary.LittleEndian.PutUint32(data[i*8:], uint32(fixed))
+	}
+	return data, nil
+}
+
+func decodeWindowsValues(data []byte) ([windowsCurvePoints]float64, error) {
+	var values [windowsCurvePoints]float64
+	if len(data) != 8*windowsCurvePoints {
+		return values, fmt.Errorf("expected %d bytes, got %d", 8*windowsCurvePoints, len(data))
+	}
+	for i := range values {
+		values[i] = float64(binary.LittleEndian.Uint32(data[i*8:])) / 65536
+	}
+	return values, nil
+}
+
+func regHex(data []byte) string {
+	hex := make([]string, len(data))
+	for i, b := range data {
+		hex[i] = fmt.Sprintf("%02x", b)
+	}
+	return strings.Join(hex, ",")
+}
+
+func (c windowsCurve) reg() (string, error) {
+	x, err := encodeWindowsValues(c.X)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", smoothMouseX, err)
+	}
+	y, err := encodeWindowsValues(c.Y)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", smoothMouseY, err)
+	}
+	var sb strings.Builder
+	sb.WriteString("Windows Registry Editor Version 5.00\r\n\r\n")
+	sb.WriteString("[HKEY_CURRENT_USER\\Control Panel\\Mouse]\r\n")
+	sb.WriteString("\"MouseSpeed\"=\"1\"\r\n")
+	sb.WriteString("\"MouseThreshold1\"=\"0\"\r\n")
+	sb.WriteString("\"MouseThreshold2\"=\"0\"\r\n")
+	fmt.Fprintf(&sb, "\"%s\"=hex:%s\r\n", smoothMouseX, regHex(x))
+	fmt.Fprintf(&sb, "\"%s\"=hex:%s\r\n", smoothMouseY, regHex(y))
+	return sb.String(), nil
+}
+
+// regedit writes UTF-16LE files with a byte order mark
+func regText(data []byte) string {
+	if len(data) < 2 || data[0] != 0xff || data[1] != 0xfe {
+		return strings.TrimPrefix(string(data), "\ufeff")
+	}
+	units := make([]uint16, (len(data)-2)/2)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(data[2+i*2:])
+	}
+	return string(utf16.Decode(units))
+}
+
+// Read both curves from a .reg file, hex values may span several lines
+func parseWindowsReg(r io.Reader) (windowsCurve, error) {
+	var curve windowsCurve
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return curve, err
+	}
+	values := map[string]string{}
+	scanner := bufio.NewScanner(strings.NewReader(regText(data)))
+	var name, value string
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if name == "" {
+			for _, key := range []string{smoothMouseX, smoothMouseY} {
+				prefix := "\"" + key + "\"=hex:"
+				if strings.HasPrefix(line, prefix) {
+					name, value = key, strings.TrimPrefix(line, prefix)
+				}
+			}
+			if name == "" {
+				continue
+			}
+		} else {
+			value += line
+		}
+		if strings.HasSuffix(value, "\\") {
+			value = strings.TrimSuffix(value, "\\")
+			continue
+		}
+		values[name] = value
+		name, value = "", ""
+	}
+	if err := scanner.Err(); err != nil {
+		return curve, err
+	}
+
+	for key, target := range map[string]*[windowsCurvePoints]float64{smoothMouseX: &curve.X, smoothMouseY: &curve.Y} {
+		hex, ok := values[key]
+		if !ok {
+			return curve, fmt.Errorf("%s not found", key)
+		}
+		var data []byte
+		for _, part := range strings.Split(hex, ",") {
+			b, err := strconv.ParseUint(strings.TrimSpace(part), 16, 8)
+			if err != nil {
+				return curve, fmt.Errorf("%s: %w", key, err)
+			}
+			data = append(data, byte(b))
+		}
+		decoded, err := decodeWindowsValues(data)
+		if err != nil {
+			return curve, fmt.Errorf("%s: %w", key, err)
+		}
+		*target = decoded
+	}
+	return curve, nil
+}
+
+func exportWindowsReg() {
+	curve, err := curveToWindows(currentCurve(), settingsDPI())
+	if err != nil {
+		errorDialog(err)
+		return
+	}
+	reg, err := curve.reg()
+	if err != nil {
+		errorDialog(err)
+		return
+	}
+	dialog.ShowFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil || writer == nil {
+			errorDialog(err)
+			return
+		}
+		defer writer.Close()
+		_, err = io.WriteString(writer, reg)
+		errorDialog(err)
+	}, fyneApp.Window)
+}
+
+func importWindowsReg() {
+	dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil || reader == nil {
+			errorDialog(err)
+			return
+		}
+		defer reader.Close()
+		curve, err := parseWindowsReg(reader)
+		if err != nil {
+			errorDialog(err)
+			return
+		}
+		base := currentConfig()
+		points := curve.toCurve(configCurve(base), settingsDPI())
+		openProfile(curveConfig(base, points), filepath.Base(reader.URI().Path())+" (unsaved)")
+	}, fyneApp.Window)
+}
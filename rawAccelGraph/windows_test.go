@@ -0,0 +1,148 @@
+package main
+
+import (
+	"encoding/binary"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+func TestWindowsValuesRoundTrip(t *testing.T) {
+	values := [windowsCurvePoints]float64{0, 0.4296875, 1.25, 3.86, 40}
+	data, err := encodeWindowsValues(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 1.25 is 0x00014000 in 16.16, the upper 4 bytes stay 0
+	if got := regHex(data[16:24]); got != "00,40,01,00,00,00,00,00" {
+		t.Errorf("1.25 encoded as %s", got)
+	}
+	decoded, err := decodeWindowsValues(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range values {
+		if diff := decoded[i] - values[i]; diff > 1.0/65536 || diff < -1.0/65536 {
+			t.Errorf("value %d = %v, want %v", i, decoded[i], values[i])
+		}
+	}
+
+	if _, err := decodeWindowsValues(data[:39]); err == nil {
+		t.Error("no error for 39 bytes")
+	}
+}
+
+func TestEncodeWindowsValuesRange(t *testing.T) {
+	for _, v := range []float64{-0.5, 65536, 1e6} {
+		values := [windowsCurvePoints]float64{0, 1, v, 3, 4}
+		if _, err := encodeWindowsValues(values); err == nil || !strings.Contains(err.Error(), "point 3") {
+			t.Errorf("%v: got %v, want an error on point 3", v, err)
+		}
+	}
+	if _, err := encodeWindowsValues([windowsCurvePoints]float64{0, 1, 2, 3, 65535.99}); err != nil {
+		t.Errorf("largest value: %v", err)
+	}
+
+	curve := windowsCurve{X: [windowsCurvePoints]float64{0, 1, 2, 3, 4}, Y: [windowsCurvePoints]float64{0, 1, 2, 3, 70000}}
+	if _, err := curve.reg(); err == nil || !strings.HasPrefix(err.Error(), smoothMouseY) {
+		t.Errorf("got %v, want a %s error", err, smoothMouseY)
+	}
+}
+
+func TestWindowsRegRoundTrip(t *testing.T) {
+	points := []curvePoint{{X: 1, Y: 1}, {X: 10, Y: 1.2}, {X: 30, Y: 1.8}, {X: 60, Y: 2}}
+	curve, err := curveToWindows(points, 800)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reg, err := curve.reg()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := parseWindowsReg(strings.NewReader(reg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range curve.X {
+		if diff := parsed.X[i] - curve.X[i]; diff > 1.0/65536 || diff < -1.0/65536 {
+			t.Errorf("X %d = %v, want %v", i, parsed.X[i], curve.X[i])
+		}
+		if diff := parsed.Y[i] - curve.Y[i]; diff > 1.0/65536 || diff < -1.0/65536 {
+			t.Errorf("Y %d = %v, want %v", i, parsed.Y[i], curve.Y[i])
+		}
+	}
+	// The points the curve was placed on come back within the fixed point precision
+	for _, spread := range windowsCurveSpread {
+		counts := 60 * spread
+		if got, want := parsed.sensitivity(counts, 800), interpolate(points, counts); got-want > 1e-3 || want-got > 1e-3 {
+			t.Errorf("sensitivity at %v = %v, want %v", counts, got, want)
+		}
+	}
+}
+
+// SmoothMouse curves as exported by regedit from a default Windows install
+const defaultWindowsReg = `Windows Registry Editor Version 5.00
+
+[HKEY_CURRENT_USER\Control Panel\Mouse]
+"MouseSpeed"="1"
+"SmoothMouseXCurve"=hex:\
+  00,00,00,00,00,00,00,00,15,6e,00,00,00,00,00,00,00,40,01,00,00,00,00,00,29,\
+  dc,03,00,00,00,00,00,00,00,28,00,00,00,00,00
+"SmoothMouseYCurve"=hex:\
+  00,00,00,00,00,00,00,00,fd,11,01,00,00,00,00,00,00,24,04,00,00,00,00,00,00,\
+  fc,12,00,00,00,00,00,00,c0,bb,01,00,00,00,00
+`
+
+func utf16Reg(text string) []byte {
+	units := utf16.Encode([]rune(text))
+	data := []byte{0xff, 0xfe}
+	for _, u := range units {
+		data = append(data, 0, 0)
+		binary.LittleEndian.PutUint16(data[len(data)-2:], u)
+	}
+	return data
+}
+
+func TestParseWindowsReg(t *testing.T) {
+	wantX := [windowsCurvePoints]float64{0, 0.43, 1.25, 3.86, 40}
+	wantY := [windowsCurvePoints]float64{0, 1.07, 4.14, 18.98, 443.75}
+	for name, src := range map[string]string{
+		"utf-8":     defaultWindowsReg,
+		"crlf":      strings.ReplaceAll(defaultWindowsReg, "\n", "\r\n"),
+		"utf-8 bom": "\ufeff" + defaultWindowsReg,
+		"utf-16":    string(utf16Reg(strings.ReplaceAll(defaultWindowsReg, "\n", "\r\n"))),
+	} {
+		curve, err := parseWindowsReg(strings.NewReader(src))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		for i := range wantX {
+			if diff := curve.X[i] - wantX[i]; diff > 0.01 || diff < -0.01 {
+				t.Errorf("%s: X %d = %v, want %v", name, i, curve.X[i], wantX[i])
+			}
+			if diff := curve.Y[i] - wantY[i]; diff > 0.01 || diff < -0.01 {
+				t.Errorf("%s: Y %d = %v, want %v", name, i, curve.Y[i], wantY[i])
+			}
+		}
+	}
+}
+
+func TestParseWindowsRegErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		msg  string
+	}{
+		{"missing Y", strings.Split(defaultWindowsReg, "\"SmoothMouseYCurve\"")[0], smoothMouseY + " not found"},
+		{"bad hex", strings.Replace(defaultWindowsReg, "15,6e", "15,zz", 1), smoothMouseX},
+		{"short value", strings.Replace(defaultWindowsReg, "00,40,01,00,00,00,00,00,29,", "29,", 1), "got 32"},
+		{"empty", "", "not found"},
+	}
+	for _, test := range tests {
+		_, err := parseWindowsReg(strings.NewReader(test.src))
+		if err == nil || !strings.Contains(err.Error(), test.msg) {
+			t.Errorf("%s: got %v, want an error with %q", test.name, err, test.msg)
+		}
+	}
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"math"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	yModeRatio       = "Ratio of X"
+	yModeIndependent = "Independent"
+)
+
+// Vertical curve, only used when profileSettings.SeparateXY is set. With a
+// ratio the Y curve follows X, else it has its own sliders.
+var yRatio float64
+var yCurve = map[float64]float64{}
+
+// Refresh the Y axis controls after a profile is loaded
+var yAxisBarRefresh func()
+
+// Same starting sensitivity as x, with ratio times its acceleration
+func ratioCurve(x []curvePoint, ratio float64) []curvePoint {
+	result := make([]curvePoint, len(x))
+	for i, p := range x {
+		result[i] = curvePoint{X: p.X, Y: x[0].Y + (p.Y-x[0].Y)*ratio}
+	}
+	return result
+}
+
+func curveY(x []curvePoint, separate bool, ratio float64, values map[float64]float64) []curvePoint {
+	if !separate || len(x) == 0 {
+		return x
+	}
+	if ratio > 0 {
+		return ratioCurve(x, ratio)
+	}
+	result := make([]curvePoint, len(x))
+	for i, p := range x {
+		y, ok := values[p.X]
+		if !ok || y == 0 {
+			y = p.Y
+		}
+		result[i] = curvePoint{X: p.X, Y: y}
+	}
+	return result
+}
+
+func currentCurveY() []curvePoint {
+	return curveY(currentCurve(), profileSettings.SeparateXY, yRatio, yCurve)
+}
+
+func configCurveY(cfg Config) []curvePoint {
+	return curveY(configCurve(cfg), cfg.ConfRawAccel.SeparateXY, cfg.ConfYRatio, cfg.ConfGraphY)
+}
+
+// Table Raw Accel receives for a curve, points left at the minimum are not exported
+func curveTable(points []curvePoint, min float64) []curvePoint {
+	var table []curvePoint
+	for _, p := range points {
+		if p.Y != min {
+			table = append(table, curvePoint{X: math.Round(p.X), Y: p.Y})
+		}
+	}
+	return table
+}
+
+// The points left out of the X table are left out of the Y one too, a ratio
+// moves them off the minimum
+func curveTableY(x, y []curvePoint, min float64) []curvePoint {
+	var table []curvePoint
+	for i, p := range y {
+		if p.Y != min && x[i].Y != min {
+			table = append(table, curvePoint{X: math.Round(p.X), Y: p.Y})
+		}
+	}
+	return table
+}
+
+func currentTableY() []curvePoint {
+	if !profileSettings.SeparateXY {
+		return dataTable(rawAccel.Data)
+	}
+	min, _ := strconv.ParseFloat(set.OrdonneesMin.Text, 64)
+	x := currentCurve()
+	return curveTableY(x, curveY(x, true, yRatio, yCurve), min)
+}
+
+func configTableY(cfg Config) []curvePoint {
+	min, _ := strconv.ParseFloat(cfg.ConfOrdonneesMin, 64)
+	x := configCurve(cfg)
+	return curveTableY(x, curveY(x, cfg.ConfRawAccel.SeparateXY, cfg.ConfYRatio, cfg.ConfGraphY), min)
+}
+
+// Table in the format of rawAccel.Data
+func tableData(table []curvePoint) map[int]string {
+	data := make(map[int]string)
+	for _, p := range table {
+		data[int(p.X)] = strconv.FormatFloat(p.Y, 'f', 3, 64)
+	}
+	return data
+}
+
+func yIndependent() bool {
+	return profileSettings.SeparateXY && yRatio <= 0
+}
+
+// Second slider grid under the X one, shown for an independent Y curve
+func genGraphY() {
+	keys := sliderKeys()
+	// Same columns as the X grid so each Y slider sits under its X slider
+	ui.RightContainerY = *container.NewGridWithColumns(len(keys))
+	ui.SlidersY = make(map[float64]*widget.Slider)
+	for _, key := range keys {
+		currentInc := key
+		x := ui.Sliders[key]
+		slider := widget.NewSlider(x.Min, x.Max)
+		slider.Orientation = widget.Vertical
+		slider.Step = x.Step
+		if y, ok := yCurve[key]; ok && y != 0 {
+			slider.Value = y
+		} else {
+			slider.Value = x.Value
+		}
+		label := canvas.NewText(strconv.FormatFloat(slider.Value, 'f', 3, 64), theme.TextColor())
+		label.TextSize = 12
+		slider.OnChanged = func(f float64) {
+			yCurve[currentInc] = f
+			label.Text = strconv.FormatFloat(f, 'f', 3, 64)
+			label.Refresh()
+			genAccelRaw()
+		}
+		ui.SlidersY[key] = slider
+		ui.RightContainerY.Add(container.NewBorder(nil, container.NewCenter(label), nil, nil, container.NewPadded(slider)))
+	}
+	layoutCurveArea()
+}
+
+func layoutCurveArea() {
+	if ui.CurveArea == nil {
+		return
+	}
+	if yIndependent() {
+		yLabel := canvas.NewText("Y curve", theme.TextColor())
+		split := container.NewVSplit(&ui.RightContainer, container.NewBorder(yLabel, nil, nil, nil, &ui.RightContainerY))
+		split.Offset = 0.6
+		ui.CurveArea.Objects = []fyne.CanvasObject{split}
+	} else {
+		ui.CurveArea.Objects = []fyne.CanvasObject{&ui.RightContainer}
+	}
+	ui.CurveArea.Refresh()
+}
+
+// Every Y slider is stored, curveY only falls back to X for missing or zero values
+func currentValuesY() map[float64]float64 {
+	if !yIndependent() {
+		return nil
+	}
+	values := make(map[float64]float64)
+	for key, slider := range ui.SlidersY {
+		values[key] = slider.Value
+	}
+	return values
+}
+
+func applyYAxis(cfg Config) {
+	yRatio = cfg.ConfYRatio
+	yCurve = map[float64]float64{}
+	for key, value := range cfg.ConfGraphY {
+		yCurve[key] = value
+	}
+	if yAxisBarRefresh != nil {
+		yAxisBarRefresh()
+	}
+}
+
+func yAxisBar() fyne.CanvasObject {
+	ratio := widget.NewEntry()
+	mode := widget.NewRadioGroup([]string{yModeRatio, yModeIndependent}, nil)
+	mode.Horizontal = true
+	separate := widget.NewCheck("Separate Y curve", nil)
+
+	update := func() {
+		if settingsTabRefresh != nil {
+			settingsTabRefresh()
+		}
+		genGraphY()
+		genAccelRaw()
+	}
+	separate.OnChanged = func(b bool) {
+		profileSettings.SeparateXY = b
+		// Raw Accel only reads the vertical table in by component mode
+		if b {
+			profileSettings.ByComponent = true
+		}
+		if b && mode.Selected == yModeRatio && yRatio <= 0 {
+			if yRatio, _ = strconv.ParseFloat(ratio.Text, 64); yRatio <= 0 {
+				yRatio = 0.5
+			}
+		}
+		if b {
+			mode.Enable()
+			ratio.Enable()
+		} else {
+			mode.Disable()
+			ratio.Disable()
+		}
+		update()
+	}
+	mode.OnChanged = func(s string) {
+		if s == yModeIndependent {
+			yRatio = 0
+			ratio.Disable()
+			// Start the Y sliders from the curve they replace
+			if len(yCurve) == 0 {
+				for _, p := range ratioCurve(currentCurve(), 1) {
+					yCurve[p.X] = p.Y
+				}
+			}
+		} else {
+			yRatio, _ = strconv.ParseFloat(ratio.Text, 64)
+			if yRatio <= 0 {
+				yRatio = 0.5
+				ratio.SetText("0.5")
+			}
+			if profileSettings.SeparateXY {
+				ratio.Enable()
+			}
+		}
+		update()
+	}
+	ratio.OnChanged = func(s string) {
+		if v, err := strconv.ParseFloat(s, 64); err == nil && v > 0 && mode.Selected == yModeRatio {
+			yRatio = v
+			genAccelRaw()
+		}
+	}
+
+	yAxisBarRefresh = func() {
+		ratio.Text = "0.5"
+		if yRatio > 0 {
+			ratio.Text = strconv.FormatFloat(yRatio, 'f', -1, 64)
+		}
+		ratio.Refresh()
+		mode.Selected = yModeIndependent
+		if yRatio > 0 || !profileSettings.SeparateXY && len(yCurve) == 0 {
+			mode.Selected = yModeRatio
+		}
+		mode.Refresh()
+		separate.Checked = profileSettings.SeparateXY
+		separate.Refresh()
+		if profileSettings.SeparateXY {
+			mode.Enable()
+			if mode.Selected == yModeRatio {
+				ratio.Enable()
+			} else {
+				ratio.Disable()
+			}
+		} else {
+			mode.Disable()
+			ratio.Disable()
+		}
+	}
+	yAxisBarRefresh()
+
+	return container.NewHBox(separate, mode, widget.NewLabel("Y acceleration ratio"), ratio)
+}
@@ -0,0 +1,95 @@
+package main
+
+import "testing"
+
+func TestRatioCurve(t *testing.T) {
+	x := []curvePoint{{X: 1, Y: 1}, {X: 10, Y: 1.5}, {X: 20, Y: 3}}
+	tests := []struct {
+		ratio float64
+		want  []float64
+	}{
+		{1, []float64{1, 1.5, 3}},
+		{0.5, []float64{1, 1.25, 2}},
+		{2, []float64{1, 2, 5}},
+		{0, []float64{1, 1, 1}},
+	}
+	for _, test := range tests {
+		got := ratioCurve(x, test.ratio)
+		for i := range test.want {
+			if got[i].X != x[i].X || !almostEqual(got[i].Y, test.want[i]) {
+				t.Errorf("ratio %v: point %d = %v, want {%v %v}", test.ratio, i, got[i], x[i].X, test.want[i])
+			}
+		}
+	}
+}
+
+func TestCurveY(t *testing.T) {
+	x := []curvePoint{{X: 1, Y: 1}, {X: 10, Y: 1.5}, {X: 20, Y: 3}}
+	values := map[float64]float64{1: 0.8, 10: 0, 30: 4}
+	tests := []struct {
+		name     string
+		separate bool
+		ratio    float64
+		values   map[float64]float64
+		want     []float64
+	}{
+		{"not separate", false, 0.5, values, []float64{1, 1.5, 3}},
+		{"ratio", true, 0.5, values, []float64{1, 1.25, 2}},
+		// Zero and missing values follow X, values off the grid are ignored
+		{"independent", true, 0, values, []float64{0.8, 1.5, 3}},
+		{"independent without values", true, 0, nil, []float64{1, 1.5, 3}},
+	}
+	for _, test := range tests {
+		got := curveY(x, test.separate, test.ratio, test.values)
+		if len(got) != len(test.want) {
+			t.Errorf("%s: got %v", test.name, got)
+			continue
+		}
+		for i := range test.want {
+			if got[i].X != x[i].X || !almostEqual(got[i].Y, test.want[i]) {
+				t.Errorf("%s: point %d = %v, want {%v %v}", test.name, i, got[i], x[i].X, test.want[i])
+			}
+		}
+	}
+	if got := curveY(nil, true, 0.5, nil); len(got) != 0 {
+		t.Errorf("empty X curve: got %v", got)
+	}
+}
+
+func TestConfigTableY(t *testing.T) {
+	cfg := Config{
+		ConfAbcisses:     "20",
+		ConfCollumns:     "2",
+		ConfOrdonneesMin: "0.5",
+		ConfOrdonneesMax: "3",
+		ConfGraph:        map[float64]float64{1: 1, 11: 2},
+		ConfGrid:         []float64{1, 11, 21},
+		ConfGraphY:       map[float64]float64{1: 1.2, 21: 2.5},
+	}
+	// The point at Ratio Min is left out like in the X table, even when the
+	// ratio or the Y sliders move it
+	tests := []struct {
+		name     string
+		separate bool
+		ratio    float64
+		want     []curvePoint
+	}{
+		{"not separate", false, 0, []curvePoint{{X: 1, Y: 1}, {X: 11, Y: 2}}},
+		{"ratio", true, 2, []curvePoint{{X: 1, Y: 1}, {X: 11, Y: 3}}},
+		{"independent", true, 0, []curvePoint{{X: 1, Y: 1.2}, {X: 11, Y: 2}}},
+	}
+	for _, test := range tests {
+		cfg.ConfRawAccel = RawAccelSettings{ByComponent: test.separate, SeparateXY: test.separate}
+		cfg.ConfYRatio = test.ratio
+		got := configTableY(cfg)
+		if len(got) != len(test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+			continue
+		}
+		for i := range test.want {
+			if got[i].X != test.want[i].X || !almostEqual(got[i].Y, test.want[i].Y) {
+				t.Errorf("%s: point %d = %v, want %v", test.name, i, got[i], test.want[i])
+			}
+		}
+	}
+}